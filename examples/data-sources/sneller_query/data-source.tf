@@ -0,0 +1,18 @@
+# Verify that the table can be queried
+data "sneller_query" "count" {
+  region   = "us-east-1"
+  database = "logs"
+  sql      = "SELECT COUNT(*) AS \"count\" FROM \"requests\""
+}
+
+# Obtain the distinct tenants in the log table
+data "sneller_query" "tenants" {
+  region   = "us-east-1"
+  database = "logs"
+  sql      = "SELECT DISTINCT tenant FROM \"requests\" ORDER BY tenant"
+  max_rows = 100
+}
+
+output "tenants" {
+  value = [for row in data.sneller_query.tenants.rows : jsondecode(row).tenant]
+}
@@ -0,0 +1,23 @@
+terraform {
+  required_providers {
+    sneller = {
+      source = "snellerinc/sneller"
+    }
+  }
+}
+
+provider "sneller" {
+  default_region = var.region
+  token          = var.sneller_token
+}
+
+variable "region" {
+  type        = string
+  description = "AWS region"
+  default     = "us-east-1"
+}
+
+variable "sneller_token" {
+  type        = string
+  description = "Sneller token"
+}
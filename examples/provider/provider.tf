@@ -3,3 +3,22 @@ provider "sneller" {
   default_region = "us-west-1"
   token          = "SA0M1xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"
 }
+
+# Token that is obtained from a credential command, which
+# writes {"token": "...", "expiration": "..."} to its output
+provider "sneller" {
+  alias         = "command"
+  token_command = ["/usr/local/bin/sneller-token", "--tenant", "TA0XXXXXXXXX"]
+}
+
+# Token, region and endpoint of a profile in ~/.sneller/config
+provider "sneller" {
+  alias   = "profile"
+  profile = "staging"
+}
+
+# Manage another tenant using a provider alias
+provider "sneller" {
+  alias     = "analytics"
+  tenant_id = "TA0XXXXXXXXX"
+}
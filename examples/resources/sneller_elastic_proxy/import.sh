@@ -1,2 +1,5 @@
 # Elastic proxy configuration can be imported by specifying the tenant and region
 terraform import sneller_elastic_proxy.test TA0XXXXXXXXX/us-east-1
+
+# The tenant can be omitted for the provider's tenant
+terraform import sneller_elastic_proxy.test us-east-1
@@ -1,2 +1,8 @@
 # Tables can be imported by specifying the tenant, region, database and table
 terraform import sneller_table.test TA0M16BTT6Z4/us-east-1/test-db/test-table
+
+# The tenant can be omitted for the provider's tenant
+terraform import sneller_table.test us-east-1/test-db/test-table
+
+# The region can be omitted as well, when the table only exists in a single region
+terraform import sneller_table.test test-db/test-table
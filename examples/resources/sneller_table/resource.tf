@@ -12,7 +12,7 @@ resource "sneller_table" "test" {
 
       json_hints =  [
         {
-          field = "timestamp", 
+          path  = "timestamp", 
           hints = ["unix_nano_seconds"]
         }
       ]
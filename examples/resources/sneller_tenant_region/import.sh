@@ -1,2 +1,5 @@
 # Region configuration can be imported by specifying the tenant and region
 terraform import sneller_tenant_region.test TA0M16BTT6Z4/us-east-1
+
+# The tenant can be omitted for the provider's tenant
+terraform import sneller_tenant_region.test us-east-1
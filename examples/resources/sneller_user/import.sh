@@ -1,2 +1,8 @@
 # Sneller user can be imported by specifying the tenant and user identifier
 terraform import sneller_user.test TA0XXXXXXXXX/12345678-1234-1234-1234-1234567890ab
+
+# The tenant can be omitted for the provider's tenant
+terraform import sneller_user.test 12345678-1234-1234-1234-1234567890ab
+
+# Users of the provider's tenant can be imported by their email address as well
+terraform import sneller_user.test user@example.com
@@ -4,7 +4,10 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
+	"terraform-provider-sneller/sneller/generate"
 	"terraform-provider-sneller/sneller/provider"
+	"terraform-provider-sneller/sneller/validate"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
@@ -12,13 +15,31 @@ import (
 // Provider documentation generation.
 //go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs generate --provider-name sneller
 
+// version is set by goreleaser at build time.
+var version = "dev"
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		// export an existing tenant as Terraform configuration
+		if err := generate.Run(context.Background(), version, os.Args[2:], os.Stderr); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "validate-table" {
+		// validate table definitions without a token
+		if err := validate.Run(context.Background(), version, os.Args[2:], os.Stdout, os.Stderr); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
 	flag.Parse()
 
-	err := providerserver.Serve(context.Background(), provider.New, providerserver.ServeOpts{
+	err := providerserver.Serve(context.Background(), provider.New(version), providerserver.ServeOpts{
 		Address:         "registry.terraform.io/SnellerInc/sneller",
 		Debug:           debug,
 		ProtocolVersion: 6,
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"terraform-provider-sneller/sneller/api"
 	"terraform-provider-sneller/sneller/provider"
+	"terraform-provider-sneller/sneller/snellertest"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -38,14 +39,30 @@ var (
 	TableName    string
 
 	TestAccProtoV6ProviderFactories map[string]func() (tfprotov6.ProviderServer, error)
+
+	// FakeServer is the in-memory Sneller API that is used when
+	// the acceptance tests run offline (nil otherwise).
+	FakeServer *snellertest.Server
 )
 
+// EnvFakeAPI forces the acceptance tests to run against the
+// in-memory Sneller API. This is also the default when no
+// Sneller token is set.
+const EnvFakeAPI = "SNELLER_FAKE_API"
+
 func init() {
 	ctx := context.Background()
 
 	token := os.Getenv(api.EnvSnellerToken)
-	if token == "" {
-		panic(fmt.Sprintf("%s must be set for acceptance tests", api.EnvSnellerToken))
+	if token == "" || os.Getenv(EnvFakeAPI) != "" {
+		FakeServer = snellertest.NewServer()
+		token = FakeServer.Token
+		os.Setenv(api.EnvSnellerToken, token)
+		os.Setenv(api.EnvSnellerApiEndpoint, FakeServer.URL)
+		os.Setenv(api.EnvSnellerQueryEndpoint, FakeServer.URL)
+		if os.Getenv("TENANT_ACCOUNT_ID") == "" {
+			TenantAccountID = "111111111111"
+		}
 	}
 
 	apiEndPoint := os.Getenv(api.EnvSnellerApiEndpoint)
@@ -102,6 +119,6 @@ func init() {
 	TableName = "test-table"
 
 	TestAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-		"sneller": providerserver.NewProtocol6WithError(provider.New()),
+		"sneller": providerserver.NewProtocol6WithError(provider.New("test")()),
 	}
 }
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// DefaultTenantCacheTTL is the default time that tenant info is
+// cached.
+const DefaultTenantCacheTTL = 5 * time.Minute
+
+var errTenantFetchPanicked = errors.New("fetching the tenant info panicked")
+
+// TenantCache caches the tenant info per tenant and region, so
+// it isn't fetched again for each resource operation. Concurrent
+// requests for the same tenant info share a single API call. The
+// tenant info of a tenant is invalidated when the client changes
+// the regional configuration.
+type TenantCache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[tenantCacheKey]*tenantCacheEntry
+}
+
+type tenantCacheKey struct {
+	tenantID, region string
+}
+
+type tenantCacheEntry struct {
+	done    chan struct{} // closed when the tenant info is fetched
+	info    *TenantInfo
+	err     error
+	expires time.Time
+}
+
+func NewTenantCache(ttl time.Duration) *TenantCache {
+	return &TenantCache{
+		TTL:     ttl,
+		entries: make(map[tenantCacheKey]*tenantCacheEntry),
+	}
+}
+
+// get returns the cached tenant info or it fetches the tenant
+// info when it isn't cached (or expired). Errors aren't cached.
+func (tc *TenantCache) get(ctx context.Context, tenantID, region string, fetch func() (*TenantInfo, error)) (*TenantInfo, error) {
+	key := tenantCacheKey{tenantID: tenantID, region: region}
+
+	tc.mu.Lock()
+	e := tc.entries[key]
+	if e == nil || e.isExpired() {
+		e = &tenantCacheEntry{done: make(chan struct{})}
+		tc.entries[key] = e
+		tc.mu.Unlock()
+		tc.fetch(key, e, fetch)
+	} else {
+		tc.mu.Unlock()
+	}
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	// return a copy, so the cached info can't be modified
+	info := *e.info
+	info.Regions = maps.Clone(e.info.Regions)
+	return &info, nil
+}
+
+// fetch fetches the tenant info of the entry. The entry is
+// completed even when fetch panics, so concurrent requests don't
+// wait forever and the next request fetches the info again.
+func (tc *TenantCache) fetch(key tenantCacheKey, e *tenantCacheEntry, fetch func() (*TenantInfo, error)) {
+	e.err = errTenantFetchPanicked
+	defer func() {
+		tc.mu.Lock()
+		defer tc.mu.Unlock()
+		e.expires = time.Now().Add(tc.TTL)
+		if e.err != nil && tc.entries[key] == e {
+			delete(tc.entries, key)
+		}
+		close(e.done)
+	}()
+	e.info, e.err = fetch()
+}
+
+// isExpired returns true if the entry has been fetched and it
+// expired. It should be called while holding the lock.
+func (e *tenantCacheEntry) isExpired() bool {
+	select {
+	case <-e.done:
+		return time.Now().After(e.expires)
+	default:
+		// the tenant info is still being fetched
+		return false
+	}
+}
+
+// Invalidate removes all cached tenant info. A tenant may be
+// cached using its ID and as the token's tenant ("me"), so the
+// tenant info of all tenants is removed.
+func (tc *TenantCache) Invalidate() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	maps.Clear(tc.entries)
+}
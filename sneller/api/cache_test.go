@@ -0,0 +1,153 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTenantCache(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	srv, _ := failingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write([]byte(`{"tenantID":"TA0TEST","regions":{"us-east-1":{"bucket":"s3://bucket"}}}`))
+	})
+
+	ctx := context.Background()
+	c := testClient(t, srv, 0)
+	c.TenantCache = NewTenantCache(time.Hour)
+
+	// concurrent calls share a single request
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Tenant(ctx, "us-east-1"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected 1 call, got %d", n)
+	}
+
+	// the cached info can't be modified
+	info, err := c.Tenant(ctx, "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(info.Regions, "us-east-1")
+	if info, _ := c.Tenant(ctx, "us-east-1"); info.Regions["us-east-1"].Bucket != "s3://bucket" {
+		t.Errorf("cached info was modified: %+v", info)
+	}
+
+	// other regions and tenants are cached separately
+	if _, err := c.Tenant(ctx, "us-west-2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WithTenant("TA0OTHER").Tenant(ctx, "us-east-1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("expected 3 calls, got %d", n)
+	}
+
+	// modifying the tenant invalidates the cache
+	if err := c.SetBucket(ctx, "us-east-1", "s3://other-bucket", "arn:aws:iam::111111111111:role/test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Tenant(ctx, "us-east-1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 4 {
+		t.Fatalf("expected 4 calls, got %d", n)
+	}
+
+	// expired info is fetched again
+	c.TenantCache.TTL = 0
+	c.TenantCache.Invalidate()
+	for i := 0; i < 2; i++ {
+		if _, err := c.Tenant(ctx, "us-east-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 6 {
+		t.Fatalf("expected 6 calls, got %d", n)
+	}
+}
+
+func TestTenantCacheError(t *testing.T) {
+	srv, calls := failingServer(t, []int{http.StatusInternalServerError}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tenantID":"TA0TEST"}`))
+	})
+
+	c := testClient(t, srv, 0)
+	c.TenantCache = NewTenantCache(time.Hour)
+	if _, err := c.Tenant(context.Background(), ""); err == nil {
+		t.Fatal("expected error")
+	}
+	info, err := c.Tenant(context.Background(), "")
+	if err != nil || info.TenantID != "TA0TEST" {
+		t.Fatalf("unexpected tenant info %v (err: %v)", info, err)
+	}
+	if n := atomic.LoadInt32(calls); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+}
+
+func TestTenantCachePanic(t *testing.T) {
+	tc := NewTenantCache(time.Hour)
+	ctx := context.Background()
+
+	// a concurrent request waits for the fetch that panics
+	started := make(chan struct{})
+	waited := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		tc.get(ctx, "TA0TEST", "", func() (*TenantInfo, error) {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			panic("fetch failed")
+		})
+	}()
+	<-started
+	go func() {
+		_, err := tc.get(ctx, "TA0TEST", "", func() (*TenantInfo, error) {
+			return nil, errors.New("unexpected fetch")
+		})
+		waited <- err
+	}()
+	select {
+	case err := <-waited:
+		if !errors.Is(err, errTenantFetchPanicked) {
+			t.Errorf("expected error %v, got %v", errTenantFetchPanicked, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request waits for the fetch that panicked")
+	}
+
+	// the next request fetches the tenant info again
+	info, err := tc.get(ctx, "TA0TEST", "", func() (*TenantInfo, error) {
+		return &TenantInfo{TenantID: "TA0TEST"}, nil
+	})
+	if err != nil || info.TenantID != "TA0TEST" {
+		t.Fatalf("unexpected tenant info %v (err: %v)", info, err)
+	}
+}
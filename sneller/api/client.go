@@ -4,42 +4,76 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-)
-
-var (
-	ErrNotFound = errors.New("not found")
+	"time"
 )
 
 type Client struct {
-	Client        *http.Client
-	tenantID      string
-	Token         string
-	DefaultRegion string
-	ApiURL        *url.URL
+	Client         *http.Client
+	TenantID       string // tenant that is managed (defaults to the token's tenant)
+	Token          string
+	TokenSource    TokenSource // obtains the token for each request (instead of Token)
+	DefaultRegion  string
+	ApiURL         *url.URL            // endpoint template (__REGION__ is replaced by the region)
+	Endpoints      map[string]*url.URL // base URLs of specific regions (overrides ApiURL)
+	QueryURL       *url.URL            // query endpoint template (defaults to DefaultQueryEndPoint)
+	QueryEndpoints map[string]*url.URL // query endpoints of specific regions (overrides QueryURL)
+	MaxRetries     int                 // maximum number of retries for failed requests
+	RetryMaxWait   time.Duration       // maximum time to wait between retries
+	RequestTimeout time.Duration       // maximum time until the response of an attempt is received (disabled when 0)
+	TenantCache    *TenantCache        // caches tenant info (disabled when nil)
+	Limiter        *Limiter            // limits the requests per region (disabled when nil)
+	Verifier       *Verifier           // verifies the access before the first request (disabled when nil)
+	UserAgent      string              // value of the User-Agent header
+}
+
+// WithTenant returns a client that manages the given tenant. The
+// client itself is returned when the tenant is empty or when it
+// already manages the tenant.
+func (c *Client) WithTenant(tenantID string) *Client {
+	if tenantID == "" || tenantID == c.TenantID {
+		return c
+	}
+	copy := *c
+	copy.TenantID = tenantID
+	return &copy
 }
 
 func (c *Client) Ping(ctx context.Context, region string) error {
 	req := c.url(ctx, http.MethodGet, region, "")
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
-	}
-	return nil
+	return checkResponse(resp)
 }
 
+// Tenant returns the tenant info. The information of all regions
+// is returned, when no region is specified.
 func (c *Client) Tenant(ctx context.Context, region string) (*TenantInfo, error) {
+	if c.TenantCache == nil {
+		return c.fetchTenant(ctx, region)
+	}
+	return c.TenantCache.get(ctx, c.TenantID, region, func() (*TenantInfo, error) {
+		return c.fetchTenant(ctx, region)
+	})
+}
+
+// invalidateTenant removes the cached tenant info, because the
+// tenant's configuration is modified.
+func (c *Client) invalidateTenant() {
+	if c.TenantCache != nil {
+		c.TenantCache.Invalidate()
+	}
+}
+
+func (c *Client) fetchTenant(ctx context.Context, region string) (*TenantInfo, error) {
 	req := c.url(ctx, http.MethodGet, "", "")
 	if region != "" {
 		q, err := url.ParseQuery(req.URL.RawQuery)
@@ -50,15 +84,14 @@ func (c *Client) Tenant(ctx context.Context, region string) (*TenantInfo, error)
 		req.URL.RawQuery = q.Encode()
 	}
 
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return nil, err
 	}
 
 	var tenantInfo TenantInfo
@@ -74,15 +107,14 @@ func (c *Client) Tenant(ctx context.Context, region string) (*TenantInfo, error)
 }
 
 func (c *Client) Users(ctx context.Context) ([]User, error) {
-	resp, err := c.client().Do(c.url(ctx, http.MethodGet, "", "/user"))
+	resp, err := c.do(c.url(ctx, http.MethodGet, "", "/user"))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return nil, err
 	}
 
 	var users []User
@@ -94,15 +126,14 @@ func (c *Client) Users(ctx context.Context) ([]User, error) {
 }
 
 func (c *Client) User(ctx context.Context, userID string) (*User, error) {
-	resp, err := c.client().Do(c.url(ctx, http.MethodGet, "", fmt.Sprintf("/user/%s", userID)))
+	resp, err := c.do(c.url(ctx, http.MethodGet, "", fmt.Sprintf("/user/%s", userID)))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return nil, err
 	}
 
 	var user User
@@ -134,15 +165,14 @@ func (c *Client) CreateUser(ctx context.Context, email string, isAdmin bool, loc
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return "", err
 	}
 
 	var userID string
@@ -179,36 +209,36 @@ func (c *Client) UpdateUser(ctx context.Context, userID string, email *string, i
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (c *Client) DeleteUser(ctx context.Context, userID string) error {
-	resp, err := c.client().Do(c.url(ctx, http.MethodDelete, "", fmt.Sprintf("/user/%s", userID)))
+	resp, err := c.do(c.url(ctx, http.MethodDelete, "", fmt.Sprintf("/user/%s", userID)))
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (c *Client) SetBucket(ctx context.Context, region, bucket, roleARN string) error {
+	defer c.invalidateTenant()
+
 	req := c.url(ctx, http.MethodPatch, region, "")
 
 	q, err := url.ParseQuery(req.URL.RawQuery)
@@ -220,21 +250,22 @@ func (c *Client) SetBucket(ctx context.Context, region, bucket, roleARN string)
 	q.Set("roleArn", roleARN)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (c *Client) ResetBucket(ctx context.Context, region string) error {
+	defer c.invalidateTenant()
+
 	req := c.url(ctx, http.MethodPatch, region, "")
 
 	q, err := url.ParseQuery(req.URL.RawQuery)
@@ -244,21 +275,22 @@ func (c *Client) ResetBucket(ctx context.Context, region string) error {
 	q.Set("operation", "resetBucket")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 func (c *Client) SetMaxScanBytes(ctx context.Context, region string, maxScanBytes *uint64) (uint64, error) {
+	defer c.invalidateTenant()
+
 	req := c.url(ctx, http.MethodPatch, region, "")
 	q, err := url.ParseQuery(req.URL.RawQuery)
 	if err != nil {
@@ -270,15 +302,14 @@ func (c *Client) SetMaxScanBytes(ctx context.Context, region string, maxScanByte
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return 0, err
 	}
 
 	var effectiveMaxScanBytes uint64
@@ -287,15 +318,14 @@ func (c *Client) SetMaxScanBytes(ctx context.Context, region string, maxScanByte
 }
 
 func (c *Client) Databases(ctx context.Context, region string) ([]string, error) {
-	resp, err := c.client().Do(c.url(ctx, http.MethodGet, region, "/db"))
+	resp, err := c.do(c.url(ctx, http.MethodGet, region, "/db"))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return nil, err
 	}
 
 	var databases []string
@@ -307,18 +337,14 @@ func (c *Client) Databases(ctx context.Context, region string) ([]string, error)
 }
 
 func (c *Client) Database(ctx context.Context, region, database string) ([]TableInfo, error) {
-	resp, err := c.client().Do(c.url(ctx, http.MethodGet, region, fmt.Sprintf("/db/%s/table", database)))
+	resp, err := c.do(c.url(ctx, http.MethodGet, region, fmt.Sprintf("/db/%s/table", database)))
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, ErrNotFound
-		}
-		msg, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return nil, err
 	}
 
 	var tables []TableInfo
@@ -329,23 +355,43 @@ func (c *Client) Database(ctx context.Context, region, database string) ([]Table
 	return tables, nil
 }
 
-func (c *Client) SetTable(ctx context.Context, region, database, table string, data []byte) error {
+// TableInfo returns the information of a single table, which
+// is obtained from the tables of the database. ErrNotFound is
+// returned when the table doesn't exist.
+func (c *Client) TableInfo(ctx context.Context, region, database, table string) (*TableInfo, error) {
+	tables, err := c.Database(ctx, region, database)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range tables {
+		if info.Name == table {
+			return &info, nil
+		}
+	}
+	return nil, fmt.Errorf("table %s/%s: %w", database, table, ErrNotFound)
+}
+
+// SetTable writes the table definition and returns the entity tag
+// of the new definition (if returned by the Sneller API). When etag
+// isn't empty, the definition is only written when it wasn't
+// modified since it was read. Otherwise, ErrPreconditionFailed is
+// returned.
+func (c *Client) SetTable(ctx context.Context, region, database, table string, data []byte, etag string) (string, error) {
 	req := c.url(ctx, http.MethodPut, region, fmt.Sprintf("/db/%s/table/%s/definition", database, table))
-	req.Header.Add("Content-Type", "application/json")
-	req.Body = io.NopCloser(bytes.NewReader(data))
+	setJSONBody(req, data)
+	setIfMatch(req, etag)
 
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return "", err
 	}
 
-	return nil
+	return resp.Header.Get(ETagHeader), nil
 }
 
 func (c *Client) DeleteTable(ctx context.Context, region, database, table string, all bool) error {
@@ -359,108 +405,101 @@ func (c *Client) DeleteTable(ctx context.Context, region, database, table string
 		req.URL.RawQuery = q.Encode()
 	}
 
-	resp, err := c.client().Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func (c *Client) Table(ctx context.Context, region, database, table string) ([]byte, error) {
-	resp, err := c.client().Do(c.url(ctx, http.MethodGet, region, fmt.Sprintf("/db/%s/table/%s/definition", database, table)))
+// Table returns the table definition and its entity tag, which
+// can be passed to SetTable to prevent overwriting concurrent
+// modifications. The entity tag is empty when the Sneller API
+// doesn't return it.
+func (c *Client) Table(ctx context.Context, region, database, table string) ([]byte, string, error) {
+	resp, err := c.do(c.url(ctx, http.MethodGet, region, fmt.Sprintf("/db/%s/table/%s/definition", database, table)))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		if resp.StatusCode == http.StatusNotFound {
-			return nil, ErrNotFound
-		}
-		msg, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return nil, "", err
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return data, nil
+	return data, resp.Header.Get(ETagHeader), nil
 }
 
-func (c *Client) ElasticProxyConfig(ctx context.Context, region string) (*ElasticProxyConfig, error) {
-	resp, err := c.client().Do(c.url(ctx, http.MethodGet, region, "/elasticproxy/config"))
+// ElasticProxyConfig returns the Elastic proxy configuration and
+// its entity tag, which can be passed to SetElasticProxyConfig to
+// prevent overwriting concurrent modifications. The entity tag is
+// empty when the Sneller API doesn't return it.
+func (c *Client) ElasticProxyConfig(ctx context.Context, region string) (*ElasticProxyConfig, string, error) {
+	resp, err := c.do(c.url(ctx, http.MethodGet, region, "/elasticproxy/config"))
 	if err != nil {
-		return nil, err
-	}
-
-	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return nil, "", err
 	}
 
 	var config ElasticProxyConfig
 	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
-		return nil, fmt.Errorf("error reading elastic proxy configuration: %s", err.Error())
+		return nil, "", fmt.Errorf("error reading elastic proxy configuration: %s", err.Error())
 	}
 
-	return &config, nil
+	return &config, resp.Header.Get(ETagHeader), nil
 }
 
-func (c *Client) SetElasticProxyConfig(ctx context.Context, region string, config ElasticProxyConfig) error {
+// SetElasticProxyConfig writes the Elastic proxy configuration and
+// returns the entity tag of the new configuration (if returned by
+// the Sneller API). When etag isn't empty, the configuration is
+// only written when it wasn't modified since it was read. Otherwise,
+// ErrPreconditionFailed is returned.
+func (c *Client) SetElasticProxyConfig(ctx context.Context, region string, config ElasticProxyConfig, etag string) (string, error) {
 	data, err := json.Marshal(config)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	req := c.url(ctx, http.MethodPut, region, "/elasticproxy/config")
-	req.Header.Add("Content-Type", "application/json")
-	req.Body = io.NopCloser(bytes.NewReader(data))
-	resp, err := c.client().Do(req)
-	if err != nil {
-		return err
-	}
-
+	setJSONBody(req, data)
+	setIfMatch(req, etag)
+	resp, err := c.do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return "", err
 	}
 
-	return nil
+	return resp.Header.Get(ETagHeader), nil
 }
 
 func (c *Client) DeleteElasticProxyConfig(ctx context.Context, region string) error {
-	resp, err := c.client().Do(c.url(ctx, http.MethodDelete, region, "/elasticproxy/config"))
-	if err != nil {
-		return err
-	}
-
+	resp, err := c.do(c.url(ctx, http.MethodDelete, region, "/elasticproxy/config"))
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		msg, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("HTTP status %d: %s", resp.StatusCode, msg)
+	if err := checkResponse(resp); err != nil {
+		return err
 	}
 
 	return nil
@@ -474,22 +513,107 @@ func (c *Client) client() *http.Client {
 	return client
 }
 
+func setJSONBody(req *http.Request, data []byte) {
+	req.Header.Add("Content-Type", "application/json")
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.ContentLength = int64(len(data))
+}
+
+// setIfMatch makes the request conditional on the entity tag,
+// unless it's empty.
+func setIfMatch(req *http.Request, etag string) {
+	if etag != "" {
+		req.Header.Set(IfMatchHeader, etag)
+	}
+}
+
+// UserAgent returns the User-Agent header that identifies the
+// provider and Terraform versions. The (optional) suffix is
+// appended to it.
+func UserAgent(providerVersion, terraformVersion, suffix string) string {
+	userAgent := "terraform-provider-sneller/" + providerVersion
+	if terraformVersion != "" {
+		userAgent += " terraform/" + terraformVersion
+	}
+	if suffix = strings.TrimSpace(suffix); suffix != "" {
+		userAgent += " " + suffix
+	}
+	return userAgent
+}
+
+// Endpoint returns the base URL of the Sneller API in the region.
+// It is obtained from the endpoint overrides or else derived from
+// the endpoint template.
+func (c *Client) Endpoint(region string) url.URL {
+	if u, ok := c.Endpoints[region]; ok {
+		return *u
+	}
+	u := *c.ApiURL
+	u.Host = strings.ReplaceAll(u.Host, "__REGION__", region)
+	return u
+}
+
+// QueryEndpoint returns the base URL of the Sneller query API in
+// the region. It is obtained from the query endpoint overrides or
+// else derived from the query endpoint template.
+func (c *Client) QueryEndpoint(region string) url.URL {
+	if u, ok := c.QueryEndpoints[region]; ok {
+		return *u
+	}
+	u := c.QueryURL
+	if u == nil {
+		u, _ = url.Parse(DefaultQueryEndPoint)
+	}
+	queryURL := *u
+	queryURL.Host = strings.ReplaceAll(queryURL.Host, "__REGION__", region)
+	return queryURL
+}
+
+// ParseEndpoint parses the base URL of the Sneller API. It should
+// be an absolute http(s) URL.
+func ParseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q isn't an absolute http(s) URL", endpoint)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("%q shouldn't have a query or fragment", endpoint)
+	}
+	return u, nil
+}
+
 func (c *Client) url(ctx context.Context, method, region, path string) *http.Request {
 	effectiveRegion := region
 	if effectiveRegion == "" {
 		effectiveRegion = c.DefaultRegion
 	}
-	tenantID := c.tenantID
+	tenantID := c.TenantID
 	if tenantID == "" {
 		tenantID = "me"
 	}
-	url := *c.ApiURL
-	url.Host = strings.ReplaceAll(url.Host, "__REGION__", effectiveRegion)
-	url.Path = fmt.Sprintf("/tenant/%s%s", tenantID, path)
+	url := c.Endpoint(effectiveRegion)
+	url.Path = fmt.Sprintf("%s/tenant/%s%s", strings.TrimSuffix(url.Path, "/"), tenantID, path)
 	req, err := http.NewRequestWithContext(ctx, method, url.String(), nil)
 	if err != nil {
 		panic(err)
 	}
-	req.Header.Add("Authorization", "Bearer "+c.Token)
+	c.setHeaders(req)
+	req.Header.Set(RegionHeader, effectiveRegion)
 	return req
 }
+
+// setHeaders sets the headers that are sent with all requests.
+func (c *Client) setHeaders(req *http.Request) {
+	if c.TokenSource == nil {
+		req.Header.Add("Authorization", "Bearer "+c.Token)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+}
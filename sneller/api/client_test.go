@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestWithTenant(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := testClient(t, srv, 0)
+	if c.WithTenant("") != c {
+		t.Error("expected the same client for an empty tenant")
+	}
+
+	other := c.WithTenant("TA0OTHER")
+	if other == c || c.TenantID != "" {
+		t.Fatal("expected a copy of the client")
+	}
+	if other.WithTenant("TA0OTHER") != other {
+		t.Error("expected the same client for the same tenant")
+	}
+
+	if _, err := c.Databases(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Databases(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 || paths[0] != "/tenant/me/db" || paths[1] != "/tenant/TA0OTHER/db" {
+		t.Errorf("unexpected paths %v", paths)
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	var requests []string
+	newServer := func(name string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, name+":"+r.URL.Path)
+			w.Write([]byte(`[]`))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	srv, other := newServer("default"), newServer("other")
+
+	ctx := context.Background()
+	c := testClient(t, srv, 0)
+	otherURL, err := ParseEndpoint(other.URL + "/sneller/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Endpoints = map[string]*url.URL{"us-west-2": otherURL}
+
+	for _, region := range []string{"us-east-1", "us-west-2"} {
+		if _, err := c.Databases(ctx, region); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(requests) != 2 || requests[0] != "default:/tenant/me/db" || requests[1] != "other:/sneller/tenant/me/db" {
+		t.Errorf("unexpected requests %v", requests)
+	}
+}
+
+func TestParseEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		ok       bool
+	}{
+		{"https://api-production.__REGION__.sneller.ai", true},
+		{"http://localhost:8080", true},
+		{"http://localhost:8080/prefix", true},
+		{"localhost:8080", false},
+		{"ftp://localhost", false},
+		{"/tenant", false},
+		{"http://localhost?region=us-east-1", false},
+	}
+	for _, tt := range tests {
+		if _, err := ParseEndpoint(tt.endpoint); (err == nil) != tt.ok {
+			t.Errorf("%s: expected valid %v, got error %v", tt.endpoint, tt.ok, err)
+		}
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	var userAgent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c := testClient(t, srv, 0)
+	c.UserAgent = UserAgent("1.2.3", "1.5.7", " pipeline/42 ")
+	if _, err := c.Databases(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if userAgent != "terraform-provider-sneller/1.2.3 terraform/1.5.7 pipeline/42" {
+		t.Errorf("unexpected User-Agent %q", userAgent)
+	}
+
+	if got := UserAgent("dev", "", ""); got != "terraform-provider-sneller/dev" {
+		t.Errorf("unexpected User-Agent %q", got)
+	}
+}
@@ -1,13 +1,18 @@
 package api
 
 const (
-	AdminGroup            = "admin"
-	EnvSnellerApiEndpoint = "SNELLER_API_ENDPOINT"
-	EnvSnellerToken       = "SNELLER_TOKEN"
-	EnvSnellerRegion      = "SNELLER_REGION"
-	DefaultApiEndPoint    = "https://api-production.__REGION__.sneller.ai"
-	DefaultSnellerRegion  = "us-east-1"
-	DefaultDbPrefix       = "db/"
+	AdminGroup              = "admin"
+	EnvSnellerApiEndpoint   = "SNELLER_API_ENDPOINT"
+	EnvSnellerQueryEndpoint = "SNELLER_QUERY_ENDPOINT"
+	EnvSnellerToken         = "SNELLER_TOKEN"
+	EnvSnellerRegion        = "SNELLER_REGION"
+	EnvSnellerTenantID      = "SNELLER_TENANT_ID"
+	EnvSnellerProfile       = "SNELLER_PROFILE"
+	EnvSnellerConfigFile    = "SNELLER_CONFIG_FILE"
+	DefaultApiEndPoint      = "https://api-production.__REGION__.sneller.ai"
+	DefaultQueryEndPoint    = "https://snellerd-production.__REGION__.sneller.ai"
+	DefaultSnellerRegion    = "us-east-1"
+	DefaultDbPrefix         = "db/"
 )
 
 var (
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	RequestIDHeader = "X-Request-Id"
+	ETagHeader      = "ETag"
+	IfMatchHeader   = "If-Match"
+
+	// RegionHeader names the region that a request is meant for,
+	// so it doesn't need to be derived from the endpoint.
+	RegionHeader = "X-Sneller-Region"
+
+	maxErrorMessageSize = 4096
+)
+
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrThrottled    = errors.New("throttled")
+	ErrBadRequest   = errors.New("bad request")
+
+	// ErrPreconditionFailed is returned when a conditional write
+	// failed, because the object was modified since it was read.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Error is returned when the Sneller API responds with a
+// non-2xx status code. Use errors.Is with ErrNotFound,
+// ErrUnauthorized, ErrThrottled, ErrBadRequest (i.e. an invalid
+// query) or ErrPreconditionFailed to check for common failures or
+// errors.As to obtain the details.
+type Error struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("HTTP status %d: %s", e.StatusCode, e.Message)
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request-id: %s)", e.RequestID)
+	}
+	return msg
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrThrottled:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
+
+// checkResponse returns an *Error when the response doesn't
+// have a 2xx status code.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorMessageSize))
+	err := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(msg)),
+		RequestID:  resp.Header.Get(RequestIDHeader),
+	}
+	if resp.Request != nil {
+		err.Method = resp.Request.Method
+		err.Path = resp.Request.URL.Path
+	}
+	return err
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorDetails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, "req-123")
+		http.Error(w, "user not found", http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := testClient(t, srv, 0)
+	_, err := c.User(context.Background(), "u1")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrThrottled) {
+		t.Fatalf("unexpected error match: %v", err)
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, apiErr.StatusCode)
+	}
+	if apiErr.Method != http.MethodGet {
+		t.Errorf("expected method %s, got %s", http.MethodGet, apiErr.Method)
+	}
+	if apiErr.Path != "/tenant/me/user/u1" {
+		t.Errorf("unexpected path %q", apiErr.Path)
+	}
+	if apiErr.Message != "user not found" {
+		t.Errorf("unexpected message %q", apiErr.Message)
+	}
+	if apiErr.RequestID != "req-123" {
+		t.Errorf("unexpected request-id %q", apiErr.RequestID)
+	}
+	if got, want := err.Error(), "HTTP status 404: user not found (request-id: req-123)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		target     error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrThrottled},
+		{http.StatusBadRequest, ErrBadRequest},
+		{http.StatusPreconditionFailed, ErrPreconditionFailed},
+	}
+	for _, tt := range tests {
+		err := error(&Error{StatusCode: tt.statusCode})
+		if !errors.Is(err, tt.target) {
+			t.Errorf("status %d should match %v", tt.statusCode, tt.target)
+		}
+	}
+}
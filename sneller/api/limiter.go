@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxConcurrentRequests = 5
+	DefaultRequestsPerSecond     = 10
+)
+
+// Limiter bounds the number of concurrent requests and the rate
+// of requests per Sneller API host. Each region has its own host,
+// so each region is limited independently.
+type Limiter struct {
+	MaxConcurrent     int     // maximum number of requests in flight per host (unlimited when 0)
+	RequestsPerSecond float64 // maximum request rate per host (unlimited when 0)
+
+	mu    sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+type hostLimiter struct {
+	sem chan struct{} // nil when the concurrency is unlimited
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewLimiter(maxConcurrent int, requestsPerSecond float64) *Limiter {
+	return &Limiter{
+		MaxConcurrent:     maxConcurrent,
+		RequestsPerSecond: requestsPerSecond,
+		hosts:             make(map[string]*hostLimiter),
+	}
+}
+
+// burst returns the number of requests that can be sent at once
+// when the host has been idle.
+func (l *Limiter) burst() float64 {
+	return math.Max(1, math.Floor(l.RequestsPerSecond))
+}
+
+func (l *Limiter) host(host string) *hostLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h := l.hosts[host]
+	if h == nil {
+		h = &hostLimiter{tokens: l.burst(), last: time.Now()}
+		if l.MaxConcurrent > 0 {
+			h.sem = make(chan struct{}, l.MaxConcurrent)
+		}
+		l.hosts[host] = h
+	}
+	return h
+}
+
+// acquire waits until a request to the host can be sent. The
+// returned function should be called when the request is done.
+func (l *Limiter) acquire(ctx context.Context, host string) (func(), error) {
+	h := l.host(host)
+	if err := l.wait(ctx, h); err != nil {
+		return nil, err
+	}
+	if h.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case h.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-h.sem })
+	}, nil
+}
+
+// wait takes a token from the token bucket of the host. Tokens
+// are reserved in order, so waiting requests are served fairly.
+func (l *Limiter) wait(ctx context.Context, h *hostLimiter) error {
+	if l.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	h.tokens = math.Min(l.burst(), h.tokens+now.Sub(h.last).Seconds()*l.RequestsPerSecond)
+	h.last = now
+	h.tokens--
+	delay := time.Duration(-h.tokens / l.RequestsPerSecond * float64(time.Second))
+	h.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, delay); err != nil {
+		// return the reserved token
+		h.mu.Lock()
+		h.tokens++
+		h.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// releaseOnClose releases the limiter when the response body
+// is closed, so the request is in flight until it's consumed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiterConcurrency(t *testing.T) {
+	var inFlight, maxInFlight int32
+	srv, _ := failingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`[]`))
+	})
+
+	c := testClient(t, srv, 0)
+	c.Limiter = NewLimiter(2, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Databases(context.Background(), ""); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&maxInFlight); n != 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", n)
+	}
+}
+
+func TestLimiterRate(t *testing.T) {
+	l := NewLimiter(0, 20)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		release, err := l.acquire(ctx, "api-production.us-east-1.sneller.ai")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	// the burst of 20 requests is allowed immediately and the
+	// next 10 requests take (at least) 500ms
+	if elapsed := time.Since(start); elapsed < 450*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("unexpected duration %s", elapsed)
+	}
+
+	// other hosts (regions) have their own limit
+	start = time.Now()
+	if _, err := l.acquire(ctx, "api-production.us-west-2.sneller.ai"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("unexpected delay %s for another host", elapsed)
+	}
+
+	// waiting is aborted when the context is cancelled
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	for i := 0; i < 30; i++ {
+		if _, err := l.acquire(ctx, "api-production.us-east-1.sneller.ai"); err != nil {
+			return
+		}
+	}
+	t.Error("expected the context to be cancelled")
+}
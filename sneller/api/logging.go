@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	// LogSubsystem is the tflog subsystem that is used to log
+	// the Sneller API requests. Its level can be set using the
+	// TF_LOG_PROVIDER_SNELLER_API environment variable.
+	LogSubsystem = "sneller_api"
+
+	maxLoggedBodySize = 2048
+	redacted          = "***"
+)
+
+// secretFieldsRegex matches JSON fields that hold secrets (i.e. the
+// passwords and token of the Elastic proxy configuration). The
+// closing quote is optional, because the body may be truncated.
+var secretFieldsRegex = regexp.MustCompile(`("(?i:password|esPassword|token)"\s*:\s*)"(?:[^"\\]|\\.)*(?:"|$)`)
+
+// LoggingTransport is an http.RoundTripper that logs the
+// request and response of each Sneller API call using tflog.
+// The bearer token and secrets in the JSON bodies are masked.
+type LoggingTransport struct {
+	Transport http.RoundTripper
+}
+
+func NewLoggingTransport(transport http.RoundTripper) *LoggingTransport {
+	return &LoggingTransport{Transport: transport}
+}
+
+func (t *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := logContext(req)
+
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Sending Sneller API request", map[string]any{
+		"method": req.Method,
+		"url":    req.URL.String(),
+		"body":   requestBody(req),
+	})
+
+	start := time.Now()
+	resp, err := t.transport().RoundTrip(req)
+	latency := time.Since(start)
+	if err != nil {
+		tflog.SubsystemDebug(ctx, LogSubsystem, "Sneller API request failed", map[string]any{
+			"method":     req.Method,
+			"url":        req.URL.String(),
+			"latency_ms": latency.Milliseconds(),
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+
+	fields := map[string]any{
+		"method":     req.Method,
+		"url":        req.URL.String(),
+		"status":     resp.StatusCode,
+		"latency_ms": latency.Milliseconds(),
+		"request_id": resp.Header.Get(RequestIDHeader),
+	}
+	if !isStreamed(req, resp) {
+		body, err := peekBody(resp)
+		if err != nil {
+			return nil, err
+		}
+		fields["body"] = formatBody(body)
+	}
+	tflog.SubsystemDebug(ctx, LogSubsystem, "Received Sneller API response", fields)
+	return resp, nil
+}
+
+func (t *LoggingTransport) transport() http.RoundTripper {
+	if t.Transport == nil {
+		return http.DefaultTransport
+	}
+	return t.Transport
+}
+
+// logContext returns a context with the Sneller API subsystem
+// logger that masks the bearer token of the request.
+func logContext(req *http.Request) context.Context {
+	ctx := tflog.NewSubsystem(req.Context(), LogSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_SNELLER_API"))
+	if token := bearerToken(req); token != "" {
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, LogSubsystem, token)
+		ctx = tflog.SubsystemMaskMessageStrings(ctx, LogSubsystem, token)
+	}
+	return ctx
+}
+
+func bearerToken(req *http.Request) string {
+	token, _ := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token)
+}
+
+// requestBody returns the (truncated) request body without
+// consuming it.
+func requestBody(req *http.Request) string {
+	if req.Body == nil || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(io.LimitReader(body, maxLoggedBodySize+1))
+	return formatBody(data)
+}
+
+// isStreamed returns whether the response is a stream of query
+// results. Its body isn't logged, because reading it would delay
+// the stream and the rows may hold sensitive data.
+func isStreamed(req *http.Request, resp *http.Response) bool {
+	if strings.HasSuffix(req.URL.Path, "/query") {
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return mediaType == QueryFormatJSONLines
+}
+
+// peekBody reads the first part of the response body and
+// restores the body, so it can still be read by the caller.
+func peekBody(resp *http.Response) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	return data, nil
+}
+
+func formatBody(data []byte) string {
+	truncated := len(data) > maxLoggedBodySize
+	if truncated {
+		data = data[:maxLoggedBodySize]
+	}
+	body := redactSecrets(string(data))
+	if truncated {
+		body += "... (truncated)"
+	}
+	return body
+}
+
+// redactSecrets masks the values of all secret JSON fields.
+func redactSecrets(body string) string {
+	return secretFieldsRegex.ReplaceAllString(body, `$1"`+redacted+`"`)
+}
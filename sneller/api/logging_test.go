@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLoggingTransport(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_SNELLER_API", "DEBUG")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, "req-123")
+		w.Write([]byte(`{"logPath":"s3://logs/","elastic":{"user":"elastic","password":"response-secret"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	c := testClient(t, srv, 0)
+	c.Client = &http.Client{Transport: NewLoggingTransport(nil)}
+	config, _, err := c.ElasticProxyConfig(ctx, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Elastic == nil || config.Elastic.Password != "response-secret" {
+		t.Fatal("response body should not be altered by logging")
+	}
+
+	logged := output.String()
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+	resp := entries[1]
+	if resp["status"] != float64(http.StatusOK) {
+		t.Errorf("unexpected status %v", resp["status"])
+	}
+	if resp["request_id"] != "req-123" {
+		t.Errorf("unexpected request-id %v", resp["request_id"])
+	}
+	if _, ok := resp["latency_ms"]; !ok {
+		t.Error("latency is not logged")
+	}
+	if url, _ := resp["url"].(string); !strings.HasSuffix(url, "/tenant/me/elasticproxy/config") {
+		t.Errorf("unexpected url %v", resp["url"])
+	}
+
+	if !strings.Contains(logged, `\"password\":\"***\"`) {
+		t.Errorf("password is not masked: %s", logged)
+	}
+	if strings.Contains(logged, "response-secret") || strings.Contains(logged, "test-token") {
+		t.Errorf("secrets are logged: %s", logged)
+	}
+}
+
+func TestLoggingTransportStream(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_SNELLER_API", "DEBUG")
+
+	// the second row is only sent after the first row was read
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", QueryFormatJSONLines)
+		w.Write([]byte(`{"secret":"row-1"}` + "\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+		w.Write([]byte(`{"secret":"row-2"}` + "\n"))
+	}))
+	t.Cleanup(srv.Close)
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+"/query", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: NewLoggingTransport(nil)}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	close(release)
+	if err != nil || line != `{"secret":"row-1"}`+"\n" {
+		t.Fatalf("unexpected first row %q (%v)", line, err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("the first row was delayed by %s", d)
+	}
+	if strings.Contains(output.String(), "row-1") {
+		t.Errorf("query results are logged: %s", output.String())
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{`{"user":"u","password":"p"}`, `{"user":"u","password":"***"}`},
+		{`{"esPassword" : "p\"q"}`, `{"esPassword" : "***"}`},
+		{`{"sneller":{"token":"abc"}}`, `{"sneller":{"token":"***"}}`},
+		{`{"password":"trunc`, `{"password":"***"`},
+		{`{"logPath":"s3://logs/"}`, `{"logPath":"s3://logs/"}`},
+	}
+	for _, tt := range tests {
+		if got := redactSecrets(tt.in); got != tt.out {
+			t.Errorf("redactSecrets(%q): expected %q, got %q", tt.in, tt.out, got)
+		}
+	}
+}
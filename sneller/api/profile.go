@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultProfile is the profile that is used when no profile
+// is specified.
+const DefaultProfile = "default"
+
+// ErrProfileNotFound is returned when the configuration file
+// doesn't contain the profile.
+var ErrProfileNotFound = errors.New("profile not found")
+
+// Profile is a named profile in the Sneller configuration file.
+type Profile struct {
+	Name        string
+	Token       string
+	Region      string
+	ApiEndpoint string
+}
+
+// DefaultConfigFile returns the path of the Sneller configuration
+// file. It defaults to ~/.sneller/config, but it can be changed
+// using the SNELLER_CONFIG_FILE environment variable.
+func DefaultConfigFile() (string, error) {
+	if filename := os.Getenv(EnvSnellerConfigFile); filename != "" {
+		return filename, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".sneller", "config"), nil
+}
+
+// LoadProfile reads a named profile from the configuration file.
+// The file has an INI syntax (that is compatible with TOML) with
+// a section per profile:
+//
+//	[default]
+//	token    = "SA0M1..."
+//	region   = "us-east-1"
+//
+//	[analytics]
+//	token    = "SA0M1..."
+//	endpoint = "https://api-production.__REGION__.sneller.ai"
+//
+// Lines that start with '#' or ';' are comments. Values may be
+// quoted. It returns an error that wraps os.ErrNotExist when the
+// file doesn't exist.
+func LoadProfile(filename, name string) (*Profile, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var profile *Profile
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: invalid section %q", filename, lineNo, line)
+			}
+			section = unquote(strings.TrimSpace(line[1 : len(line)-1]))
+			if section == name {
+				profile = &Profile{Name: name}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected 'key = value'", filename, lineNo)
+		}
+		if section != name {
+			continue
+		}
+		value = unquote(strings.TrimSpace(value))
+		switch strings.TrimSpace(key) {
+		case "token":
+			profile.Token = value
+		case "region":
+			profile.Region = value
+		case "endpoint":
+			profile.ApiEndpoint = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("%w: %q in %s", ErrProfileNotFound, name, filename)
+	}
+	return profile, nil
+}
+
+// LoadDefaultProfile reads the named profile from the default
+// configuration file. When no name is specified, then the name
+// is taken from the SNELLER_PROFILE environment variable or the
+// default profile is used. Only the default profile is optional,
+// so it returns nil (without error) when it doesn't exist.
+func LoadDefaultProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = os.Getenv(EnvSnellerProfile)
+	}
+	optional := name == ""
+	if optional {
+		name = DefaultProfile
+	}
+
+	filename, err := DefaultConfigFile()
+	if err != nil {
+		if optional {
+			return nil, nil
+		}
+		return nil, err
+	}
+	profile, err := LoadProfile(filename, name)
+	if err != nil && optional && (errors.Is(err, os.ErrNotExist) || errors.Is(err, ErrProfileNotFound)) {
+		return nil, nil
+	}
+	return profile, err
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		if value[0] == '"' {
+			if s, err := strconv.Unquote(value); err == nil {
+				return s
+			}
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `
+# Sneller configuration
+[default]
+token  = "SA0M1default"
+region = us-west-2
+
+; staging environment
+[staging]
+token    = 'SA0M1staging'
+endpoint = "https://api-staging.__REGION__.sneller.ai"
+`
+
+func TestLoadProfile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(filename, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := LoadProfile(filename, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *profile != (Profile{Name: "default", Token: "SA0M1default", Region: "us-west-2"}) {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+
+	profile, err = LoadProfile(filename, "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *profile != (Profile{Name: "staging", Token: "SA0M1staging", ApiEndpoint: "https://api-staging.__REGION__.sneller.ai"}) {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+
+	if _, err := LoadProfile(filename, "production"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected profile not found, got %v", err)
+	}
+	if _, err := LoadProfile(filepath.Join(t.TempDir(), "missing"), "default"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected not exist, got %v", err)
+	}
+}
+
+func TestLoadDefaultProfile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config")
+	t.Setenv(EnvSnellerConfigFile, filename)
+	t.Setenv(EnvSnellerProfile, "")
+
+	// the default profile is optional
+	if profile, err := LoadDefaultProfile(""); profile != nil || err != nil {
+		t.Errorf("expected no profile, got %v (err: %v)", profile, err)
+	}
+	if _, err := LoadDefaultProfile("staging"); err == nil {
+		t.Error("expected error for a missing profile")
+	}
+
+	if err := os.WriteFile(filename, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvSnellerProfile, "staging")
+	profile, err := LoadDefaultProfile("")
+	if err != nil || profile.Name != "staging" {
+		t.Errorf("unexpected profile %v (err: %v)", profile, err)
+	}
+	profile, err = LoadDefaultProfile("default")
+	if err != nil || profile.Token != "SA0M1default" {
+		t.Errorf("unexpected profile %v (err: %v)", profile, err)
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Result formats of a query.
+const (
+	QueryFormatJSONLines = "application/x-ndjson"
+	QueryFormatION       = "application/ion"
+)
+
+// BytesScannedHeader holds the number of bytes that the query
+// scanned. It is sent as a trailer, because it's only known when
+// the query completed.
+const BytesScannedHeader = "X-Sneller-Bytes-Scanned"
+
+// QueryOptions are the (optional) settings of a query.
+type QueryOptions struct {
+	Format       string // result format (defaults to QueryFormatJSONLines)
+	MaxScanBytes uint64 // maximum number of bytes that the query may scan (defaults to the limit of the tenant)
+}
+
+// QueryResult streams the result of a query. Either the raw
+// result is read using Read or the rows are decoded one by one
+// using Next (JSON lines only). It should be closed when finished.
+type QueryResult struct {
+	Format string
+
+	resp *http.Response
+	dec  *json.Decoder
+}
+
+// Query runs the SQL query against the database and streams the
+// result as JSON lines. See QueryWithOptions for details.
+func (c *Client) Query(ctx context.Context, region, database, sql string) (*QueryResult, error) {
+	return c.QueryWithOptions(ctx, region, database, sql, QueryOptions{})
+}
+
+// QueryWithOptions runs the SQL query against the database and
+// streams the result. The query is aborted when the context is
+// cancelled. The Sneller API rejects queries that would scan more
+// bytes than allowed, which is reported as an *Error just like
+// invalid queries (use errors.Is with ErrBadRequest).
+func (c *Client) QueryWithOptions(ctx context.Context, region, database, sql string, opts QueryOptions) (*QueryResult, error) {
+	format := opts.Format
+	if format == "" {
+		format = QueryFormatJSONLines
+	}
+	if format != QueryFormatJSONLines && format != QueryFormatION {
+		return nil, fmt.Errorf("unsupported query result format %q", format)
+	}
+
+	req := c.queryURL(ctx, region, database, opts.MaxScanBytes)
+	req.Header.Set("Content-Type", "application/sql")
+	req.Header.Set("Accept", format)
+	req.Body = io.NopCloser(strings.NewReader(sql))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(sql)), nil
+	}
+	req.ContentLength = int64(len(sql))
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return &QueryResult{Format: format, resp: resp}, nil
+}
+
+// Read reads the raw query result.
+func (r *QueryResult) Read(p []byte) (int, error) {
+	return r.resp.Body.Read(p)
+}
+
+// Next decodes the next row of the query result into v. Numbers
+// are decoded as json.Number when v holds an interface. It returns
+// io.EOF when all rows have been read.
+func (r *QueryResult) Next(v any) error {
+	if r.Format != QueryFormatJSONLines {
+		return fmt.Errorf("rows can't be decoded from %s results", r.Format)
+	}
+	if r.dec == nil {
+		r.dec = json.NewDecoder(r.resp.Body)
+		r.dec.UseNumber()
+	}
+	err := r.dec.Decode(v)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error reading query result: %w", err)
+	}
+	return err
+}
+
+// BytesScanned returns the number of bytes that the query scanned.
+// It's only known when the whole result has been read and it's 0
+// when the Sneller API didn't report it.
+func (r *QueryResult) BytesScanned() uint64 {
+	value := r.resp.Trailer.Get(BytesScannedHeader)
+	if value == "" {
+		value = r.resp.Header.Get(BytesScannedHeader)
+	}
+	n, _ := strconv.ParseUint(value, 10, 64)
+	return n
+}
+
+// Close releases the connection of the query result. The query
+// is aborted when the result hasn't been read completely.
+func (r *QueryResult) Close() error {
+	return r.resp.Body.Close()
+}
+
+// queryURL returns the request to query the database in the
+// region. Queries are read-only, so the request is retried like
+// idempotent requests.
+func (c *Client) queryURL(ctx context.Context, region, database string, maxScanBytes uint64) *http.Request {
+	effectiveRegion := region
+	if effectiveRegion == "" {
+		effectiveRegion = c.DefaultRegion
+	}
+	queryURL := c.QueryEndpoint(effectiveRegion)
+	queryURL.Path = strings.TrimSuffix(queryURL.Path, "/") + "/query"
+	q := url.Values{"database": []string{database}}
+	if maxScanBytes > 0 {
+		q.Set("max_scan_bytes", strconv.FormatUint(maxScanBytes, 10))
+	}
+	queryURL.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(withReadOnly(ctx), http.MethodPost, queryURL.String(), nil)
+	if err != nil {
+		panic(err)
+	}
+	c.setHeaders(req)
+	req.Header.Set(RegionHeader, effectiveRegion)
+	return req
+}
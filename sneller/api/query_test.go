@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueryRequest(t *testing.T) {
+	srv, _ := failingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.URL.Path != "/query" || string(body) != "SELECT 1" ||
+			r.URL.Query().Get("database") != "db" || r.URL.Query().Get("max_scan_bytes") != "1024" ||
+			r.Header.Get("Accept") != QueryFormatION {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("\xe0\x01\x00\xea"))
+	})
+
+	c := testClient(t, srv, 0)
+	c.QueryURL = c.ApiURL
+	result, err := c.QueryWithOptions(context.Background(), "", "db", "SELECT 1", QueryOptions{
+		Format:       QueryFormatION,
+		MaxScanBytes: 1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Close()
+	data, err := io.ReadAll(result)
+	if err != nil || string(data) != "\xe0\x01\x00\xea" {
+		t.Errorf("unexpected result %q (err: %v)", data, err)
+	}
+	var row any
+	if err := result.Next(&row); err == nil {
+		t.Error("expected an error when decoding ION rows")
+	}
+}
+
+func TestQueryCancel(t *testing.T) {
+	srv, _ := failingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		enc := json.NewEncoder(w)
+		for i := 0; ; i++ {
+			if err := enc.Encode(map[string]int{"i": i}); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := testClient(t, srv, 0)
+	c.QueryURL = c.ApiURL
+	result, err := c.Query(ctx, "", "db", "SELECT * FROM table")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Close()
+
+	var row map[string]json.Number
+	if err := result.Next(&row); err != nil || row["i"] != "0" {
+		t.Fatalf("unexpected row %v (err: %v)", row, err)
+	}
+	cancel()
+	for i := 0; i < 100; i++ {
+		if err = result.Next(&row); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the query to be cancelled, got %v", err)
+	}
+}
+
+func TestQueryEndpointRetry(t *testing.T) {
+	srv, calls := failingServer(t, []int{http.StatusServiceUnavailable, http.StatusBadGateway}, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "SELECT 1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"x":1}` + "\n"))
+	})
+
+	// queries are read-only, so they are retried and they use the
+	// query endpoint of the region
+	c := testClient(t, srv, 2)
+	c.QueryURL, _ = url.Parse("http://unreachable.__REGION__.invalid")
+	c.QueryEndpoints = map[string]*url.URL{"eu-west-1": c.ApiURL}
+	result, err := c.Query(context.Background(), "eu-west-1", "db", "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Close()
+	var row map[string]json.Number
+	if err := result.Next(&row); err != nil || row["x"] != "1" {
+		t.Errorf("unexpected row %v (err: %v)", row, err)
+	}
+	if n := atomic.LoadInt32(calls); n != 3 {
+		t.Errorf("expected 3 calls, got %d", n)
+	}
+}
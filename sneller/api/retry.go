@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 4
+	DefaultRetryMaxWait = 30 * time.Second
+
+	retryMinWait = 500 * time.Millisecond
+)
+
+// do sends the request and retries it when the Sneller API is
+// throttling or temporarily unavailable. Requests that are not
+// idempotent (POST) are only retried when it is certain that the
+// server didn't process the request.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.Verifier != nil {
+		if err := c.Verifier.verify(req.Context(), c); err != nil {
+			return nil, err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		if c.TokenSource != nil {
+			// the token may have been refreshed since the last attempt
+			token, err := c.TokenSource.Token(req.Context())
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.send(req)
+		if attempt >= c.MaxRetries || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt, resp)
+		if resp != nil {
+			// drain the body, so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send sends a single request. The request is delayed when the
+// limiter doesn't allow it to be sent yet.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.Limiter == nil {
+		return c.doAttempt(req)
+	}
+	release, err := c.Limiter.acquire(req.Context(), req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.doAttempt(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// doAttempt sends a single attempt of the request. The request
+// timeout limits the time until the response is received, but not
+// reading the response body, so streamed responses (i.e. query
+// results) aren't cut off.
+func (c *Client) doAttempt(req *http.Request) (*http.Response, error) {
+	if c.RequestTimeout <= 0 {
+		return c.client().Do(req)
+	}
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(c.RequestTimeout, cancel)
+	resp, err := c.client().Do(req.WithContext(ctx))
+	if !timer.Stop() {
+		// the timeout expired before the response was received
+		if resp != nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, &timeoutError{timeout: c.RequestTimeout}
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// timeoutError is returned when an attempt exceeds the request
+// timeout. It's a net.Error, so idempotent requests are retried.
+type timeoutError struct {
+	timeout time.Duration
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("no response within the request timeout of %s", e.timeout)
+}
+
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+
+// cancelOnClose cancels the context of the attempt when the
+// response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
+
+// shouldRetry determines if the request can be retried based on
+// the response (or error) of the last attempt.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return false
+		}
+		if !isIdempotent(req) {
+			return isNotSent(err)
+		}
+		return isTransient(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		// throttled requests are never processed
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(req)
+	}
+	return false
+}
+
+// isIdempotent returns true for requests that can be safely
+// retried. All PATCH operations of the Sneller API set a value,
+// so they can safely be applied more than once. POST requests
+// are idempotent when they are marked as read-only (queries).
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return isReadOnly(req.Context())
+}
+
+type readOnlyKey struct{}
+
+// withReadOnly marks the requests of the context as read-only,
+// so they can be retried regardless of their method.
+func withReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+func isReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
+}
+
+// isNotSent returns true if the request failed before it was
+// sent to the server (i.e. the connection couldn't be made).
+func isNotSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsTemporary
+}
+
+func isTransient(err error) bool {
+	if isNotSent(err) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff returns the time to wait before the next attempt. It
+// honors the Retry-After header and falls back to exponential
+// backoff with full jitter otherwise.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	maxWait := c.RetryMaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultRetryMaxWait
+	}
+
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return min(wait, maxWait)
+		}
+	}
+
+	wait := retryMinWait << attempt
+	if wait <= 0 || wait > maxWait {
+		wait = maxWait
+	}
+	return time.Duration(rand.Int63n(int64(wait) + 1))
+}
+
+// retryAfter parses the Retry-After header, which can either
+// hold the number of seconds or an HTTP date.
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		wait := t.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
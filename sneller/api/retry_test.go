@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingServer returns a test server that responds with the
+// given status codes (in order) and succeeds afterwards.
+func failingServer(t *testing.T, statusCodes []int, handler http.HandlerFunc) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n <= len(statusCodes) {
+			statusCode := statusCodes[n-1]
+			if statusCode == 0 {
+				// simulate a connection reset
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err != nil {
+					t.Fatal(err)
+				}
+				conn.Close()
+				return
+			}
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(statusCode)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func testClient(t *testing.T, srv *httptest.Server, maxRetries int) *Client {
+	apiURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{
+		Token:         "test-token",
+		DefaultRegion: DefaultSnellerRegion,
+		ApiURL:        apiURL,
+		MaxRetries:    maxRetries,
+		RetryMaxWait:  10 * time.Millisecond,
+	}
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	srv, calls := failingServer(t, []int{http.StatusServiceUnavailable, http.StatusBadGateway, 0, http.StatusTooManyRequests}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`["db1","db2"]`))
+	})
+
+	c := testClient(t, srv, 4)
+	databases, err := c.Databases(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(databases) != 2 {
+		t.Fatalf("expected 2 databases, got %d", len(databases))
+	}
+	if got := atomic.LoadInt32(calls); got != 5 {
+		t.Fatalf("expected 5 calls, got %d", got)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	srv, calls := failingServer(t, []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	})
+
+	c := testClient(t, srv, 2)
+	if _, err := c.Databases(context.Background(), ""); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Fatalf("expected 3 calls, got %d", got)
+	}
+}
+
+func TestRetryReplaysBody(t *testing.T) {
+	const definition = `{"input":[{"pattern":"s3://bucket/*.ndjson","format":"json"}]}`
+	srv, calls := failingServer(t, []int{http.StatusGatewayTimeout}, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != definition {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	c := testClient(t, srv, 1)
+	if _, err := c.SetTable(context.Background(), "", "db", "table", []byte(definition), ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Fatalf("expected 2 calls, got %d", got)
+	}
+}
+
+func TestRetryCreateUser(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`"user-id"`))
+	}
+
+	// throttled requests are never processed, so they are retried
+	srv, calls := failingServer(t, []int{http.StatusTooManyRequests}, handler)
+	c := testClient(t, srv, 4)
+	userID, err := c.CreateUser(context.Background(), "john.doe@example.com", false, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userID != "user-id" {
+		t.Fatalf("expected user-id, got %q", userID)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Fatalf("expected 2 calls, got %d", got)
+	}
+
+	// the user may have been created, so it shouldn't be retried
+	srv, calls = failingServer(t, []int{http.StatusBadGateway}, handler)
+	c = testClient(t, srv, 4)
+	if _, err := c.CreateUser(context.Background(), "john.doe@example.com", false, nil, nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Fatalf("expected 1 call, got %d", got)
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := testClient(t, srv, 4)
+	c.RetryMaxWait = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.Databases(ctx, ""); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected 1 call, got %d", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		wait  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"Fri, 01 Sep 2023 12:00:10 GMT", 10 * time.Second, true},
+		{"Fri, 01 Sep 2023 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, test := range tests {
+		wait, ok := retryAfter(test.value, now)
+		if wait != test.wait || ok != test.ok {
+			t.Errorf("retryAfter(%q): expected (%v, %v), got (%v, %v)", test.value, test.wait, test.ok, wait, ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := &Client{RetryMaxWait: 2 * time.Second}
+	for attempt := 0; attempt < 10; attempt++ {
+		if wait := c.backoff(attempt, nil); wait < 0 || wait > 2*time.Second {
+			t.Fatalf("attempt %d: wait %v exceeds maximum wait", attempt, wait)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"60"}}}
+	if wait := c.backoff(0, resp); wait != 2*time.Second {
+		t.Fatalf("expected Retry-After to be capped at 2s, got %v", wait)
+	}
+	resp.Header.Set("Retry-After", "1")
+	if wait := c.backoff(0, resp); wait != time.Second {
+		t.Fatalf("expected Retry-After of 1s, got %v", wait)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// the first attempt doesn't respond in time
+			time.Sleep(200 * time.Millisecond)
+		}
+		// the body is streamed for longer than the timeout
+		w.Write([]byte(`["db1",`))
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`"db2"]`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := testClient(t, srv, 1)
+	c.RequestTimeout = 50 * time.Millisecond
+	databases, err := c.Databases(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(databases) != 2 {
+		t.Errorf("expected 2 databases, got %v", databases)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected 2 calls, got %d", n)
+	}
+
+	// the request fails when no attempt responds in time
+	atomic.StoreInt32(&calls, 0)
+	c.MaxRetries = 0
+	var netErr net.Error
+	if _, err := c.Databases(context.Background(), ""); !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
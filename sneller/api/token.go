@@ -0,0 +1,104 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryWindow is the time before the expiration of a token
+// that a new token is obtained, so a token doesn't expire while
+// a request is in progress.
+const tokenExpiryWindow = time.Minute
+
+// TokenSource provides the token that is used to authenticate to
+// the Sneller API. It's used instead of Client.Token when it is
+// set and it is invoked for each request, so it can refresh the
+// token when it expires.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// ReadTokenFile reads the token from the file. Leading and
+// trailing whitespace (i.e. a trailing newline) is ignored.
+func ReadTokenFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %q is empty", filename)
+	}
+	return token, nil
+}
+
+// CommandTokenSource obtains the token by running a command
+// (similar to an AWS credential process). The command should
+// write a JSON object with the token to its standard output:
+//
+//	{"token": "SA0M1...", "expiration": "2023-06-01T12:00:00Z"}
+//
+// The expiration (RFC 3339) is optional. When it is set, then
+// the command is run again when the token is about to expire.
+type CommandTokenSource struct {
+	Command []string
+
+	mu         sync.Mutex
+	token      string
+	expiration time.Time
+}
+
+var _ TokenSource = &CommandTokenSource{}
+
+func NewCommandTokenSource(command []string) *CommandTokenSource {
+	return &CommandTokenSource{Command: command}
+}
+
+func (s *CommandTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiration.IsZero() || time.Until(s.expiration) > tokenExpiryWindow) {
+		return s.token, nil
+	}
+	if len(s.Command) == 0 {
+		return "", errors.New("no token command")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.Command[0], s.Command[1:]...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("token command %q failed: %w: %s", s.Command[0], err, msg)
+		}
+		return "", fmt.Errorf("token command %q failed: %w", s.Command[0], err)
+	}
+
+	var result struct {
+		Token      string     `json:"token"`
+		Expiration *time.Time `json:"expiration,omitempty"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return "", fmt.Errorf("token command %q returned invalid output: %w", s.Command[0], err)
+	}
+	if result.Token == "" {
+		return "", fmt.Errorf("token command %q didn't return a token", s.Command[0])
+	}
+
+	s.token = result.Token
+	s.expiration = time.Time{}
+	if result.Expiration != nil {
+		s.expiration = *result.Expiration
+	}
+	return s.token, nil
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTokenFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "token")
+	if err := os.WriteFile(filename, []byte("SA0M1token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	token, err := ReadTokenFile(filename)
+	if err != nil || token != "SA0M1token" {
+		t.Errorf("unexpected token %q (err: %v)", token, err)
+	}
+
+	if err := os.WriteFile(filename, []byte(" \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTokenFile(filename); err == nil {
+		t.Error("expected error for an empty token file")
+	}
+}
+
+// tokenCommand returns a command that writes the output and
+// appends a line to the file each time it is run.
+func tokenCommand(t *testing.T, output string) ([]string, func() int) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	script := fmt.Sprintf("echo >> %q; echo '%s'", calls, output)
+	return []string{"sh", "-c", script}, func() int {
+		data, _ := os.ReadFile(calls)
+		return strings.Count(string(data), "\n")
+	}
+}
+
+func TestCommandTokenSource(t *testing.T) {
+	ctx := context.Background()
+
+	command, calls := tokenCommand(t, `{"token":"SA0M1static"}`)
+	s := NewCommandTokenSource(command)
+	for i := 0; i < 2; i++ {
+		token, err := s.Token(ctx)
+		if err != nil || token != "SA0M1static" {
+			t.Fatalf("unexpected token %q (err: %v)", token, err)
+		}
+	}
+	if n := calls(); n != 1 {
+		t.Errorf("expected the command to run once, got %d", n)
+	}
+
+	// tokens that (almost) expired are refreshed
+	expiration := time.Now().Add(tokenExpiryWindow / 2).UTC().Format(time.RFC3339)
+	command, calls = tokenCommand(t, `{"token":"SA0M1expiring","expiration":"`+expiration+`"}`)
+	s = NewCommandTokenSource(command)
+	for i := 0; i < 2; i++ {
+		if _, err := s.Token(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := calls(); n != 2 {
+		t.Errorf("expected the command to run twice, got %d", n)
+	}
+
+	for _, output := range []string{`not json`, `{"expiration":"2030-01-01T00:00:00Z"}`} {
+		command, _ = tokenCommand(t, output)
+		if _, err := NewCommandTokenSource(command).Token(ctx); err == nil {
+			t.Errorf("expected error for output %s", output)
+		}
+	}
+	if _, err := NewCommandTokenSource([]string{"sh", "-c", "echo failed >&2; exit 1"}).Token(ctx); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("expected command error, got %v", err)
+	}
+}
+
+func TestClientTokenSource(t *testing.T) {
+	srv, _ := failingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer SA0M1command" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+	command, _ := tokenCommand(t, `{"token":"SA0M1command"}`)
+	c := testClient(t, srv, 0)
+	c.TokenSource = NewCommandTokenSource(command)
+	if _, err := c.Databases(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+}
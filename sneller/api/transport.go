@@ -0,0 +1,54 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// TransportConfig configures the HTTP transport that is used to
+// access the Sneller API.
+type TransportConfig struct {
+	ProxyURL           *url.URL // proxy for all requests (defaults to the HTTP_PROXY and HTTPS_PROXY environment variables)
+	CABundle           []byte   // PEM encoded CA certificates that are trusted in addition to the system's CAs
+	ClientCert         []byte   // PEM encoded client certificate for mutual TLS
+	ClientKey          []byte   // PEM encoded private key of the client certificate
+	InsecureSkipVerify bool     // don't verify the server certificate (intended for local testing only)
+}
+
+// NewTransport returns a dedicated HTTP transport that is based on
+// the default transport and configured using the configuration.
+func NewTransport(config TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.ProxyURL != nil {
+		transport.Proxy = http.ProxyURL(config.ProxyURL)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+	if len(config.CABundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(config.CABundle) {
+			return nil, errors.New("the CA bundle doesn't contain any PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(config.ClientCert) > 0 || len(config.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Verifier verifies the access to the Sneller API before the
+// first request is sent, so the API is only contacted when it's
+// actually used. A successful verification is remembered, but a
+// failed verification is retried by the next request.
+type Verifier struct {
+	Regions []string // regions that are verified
+
+	mu       sync.Mutex
+	verified map[string]bool
+}
+
+func NewVerifier(regions ...string) *Verifier {
+	return &Verifier{Regions: regions}
+}
+
+// verify pings all regions using the client, unless they have
+// been verified already. The lock isn't held while pinging, so a
+// slow region doesn't block requests that are canceled meanwhile.
+// Concurrent requests may ping the same region, which is harmless.
+func (v *Verifier) verify(ctx context.Context, c *Client) error {
+	copy := *c
+	copy.Verifier = nil
+	for _, region := range v.Regions {
+		if v.isVerified(region) {
+			continue
+		}
+		if err := copy.Ping(ctx, region); err != nil {
+			return fmt.Errorf("the Sneller API cannot be contacted in region %s: %w", region, err)
+		}
+		v.mu.Lock()
+		if v.verified == nil {
+			v.verified = make(map[string]bool)
+		}
+		v.verified[region] = true
+		v.mu.Unlock()
+	}
+	return nil
+}
+
+func (v *Verifier) isVerified(region string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.verified[region]
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifier(t *testing.T) {
+	var pings, requests []string
+	up := false
+	srv, _ := failingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tenant/me" {
+			pings = append(pings, r.URL.Path)
+			if !up {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			return
+		}
+		requests = append(requests, r.URL.Path)
+		w.Write([]byte(`[]`))
+	})
+
+	ctx := context.Background()
+	c := testClient(t, srv, 0)
+	c.Verifier = NewVerifier("us-east-1", "us-west-2")
+
+	// a failed verification is reported and retried
+	if _, err := c.Databases(ctx, ""); !errors.Is(err, ErrUnauthorized) || !strings.Contains(err.Error(), "us-east-1") {
+		t.Fatalf("expected unauthorized error for us-east-1, got %v", err)
+	}
+	up = true
+	for i := 0; i < 2; i++ {
+		if _, err := c.Databases(ctx, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(pings) != 3 || len(requests) != 2 {
+		t.Errorf("unexpected pings %v and requests %v", pings, requests)
+	}
+}
+
+func TestVerifierSlowRegion(t *testing.T) {
+	release := make(chan struct{})
+	srv, _ := failingServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tenant/me" && r.Header.Get(RegionHeader) == "us-west-2" {
+			<-release
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+	defer close(release)
+
+	ctx := context.Background()
+	c := testClient(t, srv, 0)
+	c.Verifier = NewVerifier("us-east-1", "us-west-2")
+
+	// a request that waits for the slow region doesn't block
+	// other requests until it's verified
+	go c.Databases(ctx, "")
+	time.Sleep(50 * time.Millisecond)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Databases(timeoutCtx, "")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request is blocked by the verification of another request")
+	}
+}
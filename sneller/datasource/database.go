@@ -2,6 +2,7 @@ package datasource
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -9,6 +10,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 )
 
 func NewDatabaseDataSource() datasource.DataSource {
@@ -75,6 +77,9 @@ func (d *databaseDataSource) Configure(ctx context.Context, req datasource.Confi
 }
 
 func (d *databaseDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !providerdata.CheckClient(d.client, &resp.Diagnostics) {
+		return
+	}
 
 	var data databaseDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -109,7 +114,7 @@ func (d *databaseDataSource) Read(ctx context.Context, req datasource.ReadReques
 
 	tableInfos, err := d.client.Database(ctx, region, database)
 	if err != nil {
-		if err == api.ErrNotFound {
+		if errors.Is(err, api.ErrNotFound) {
 			resp.Diagnostics.AddError(
 				"Database not found",
 				fmt.Sprintf("Database %q not found (region %s)", database, region),
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -70,6 +71,9 @@ func (d *databasesDataSource) Configure(ctx context.Context, req datasource.Conf
 }
 
 func (d *databasesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !providerdata.CheckClient(d.client, &resp.Diagnostics) {
+		return
+	}
 
 	var data databasesDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
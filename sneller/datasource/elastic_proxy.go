@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -165,6 +166,9 @@ func (d *elasticProxyDataSource) Configure(ctx context.Context, req datasource.C
 }
 
 func (d *elasticProxyDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !providerdata.CheckClient(d.client, &resp.Diagnostics) {
+		return
+	}
 
 	var data elasticProxyDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -196,7 +200,7 @@ func (d *elasticProxyDataSource) Read(ctx context.Context, req datasource.ReadRe
 	}
 
 	elasticProxyPath := fmt.Sprintf("%s/%selastic-proxy.json", tenantInfo.Regions[region].Bucket, api.DefaultDbPrefix)
-	config, err := d.client.ElasticProxyConfig(ctx, region)
+	config, _, err := d.client.ElasticProxyConfig(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get elastic-proxy configuration",
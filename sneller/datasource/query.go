@@ -0,0 +1,204 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const defaultQueryMaxRows = 1000
+
+func NewQueryDataSource() datasource.DataSource {
+	return &queryDataSource{}
+}
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &queryDataSource{}
+	_ datasource.DataSourceWithConfigure = &queryDataSource{}
+)
+
+type queryDataSource struct {
+	client *api.Client
+}
+
+type queryDataSourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	Region       types.String `tfsdk:"region"`
+	Database     types.String `tfsdk:"database"`
+	SQL          types.String `tfsdk:"sql"`
+	MaxRows      types.Int64  `tfsdk:"max_rows"`
+	Rows         []string     `tfsdk:"rows"`
+	RowCount     types.Int64  `tfsdk:"row_count"`
+	BytesScanned types.Int64  `tfsdk:"bytes_scanned"`
+}
+
+func (d *queryDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_query"
+}
+
+func (d *queryDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Runs a SQL query (i.e. to verify that a table can be queried or to use its results in other resources).",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Terraform identifier.",
+				Computed:    true,
+			},
+			"region": schema.StringAttribute{
+				Description: "Region in which the query runs. When not set, then it default's to the tenant's home region.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"database": schema.StringAttribute{
+				Description: "Database that is queried.",
+				Required:    true,
+			},
+			"sql": schema.StringAttribute{
+				Description: "SQL query.",
+				Required:    true,
+			},
+			"max_rows": schema.Int64Attribute{
+				Description: fmt.Sprintf("Maximum number of rows that are returned. The query fails when it returns more rows. "+
+					"It defaults to %d.", defaultQueryMaxRows),
+				Optional:   true,
+				Validators: []validator.Int64{int64validator.AtLeast(1)},
+			},
+			"rows": schema.ListAttribute{
+				Description:         "Rows of the query result. Each row is a JSON object (use jsondecode to decode it).",
+				MarkdownDescription: "Rows of the query result. Each row is a JSON object (use `jsondecode` to decode it).",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"row_count": schema.Int64Attribute{
+				Description: "Number of rows of the query result.",
+				Computed:    true,
+			},
+			"bytes_scanned": schema.Int64Attribute{
+				Description: "Number of bytes that the query scanned (0 when it isn't reported by the Sneller API).",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *queryDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	d.client = req.ProviderData.(*api.Client)
+}
+
+func (d *queryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !providerdata.CheckClient(d.client, &resp.Diagnostics) {
+		return
+	}
+
+	var data queryDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tenantInfo, err := d.client.Tenant(ctx, "")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Cannot get tenant info",
+			fmt.Sprintf("Unable to get tenant info: %v", err.Error()),
+		)
+		return
+	}
+	if d.client.TenantID != "" {
+		// queries run as the token's tenant, so the region and
+		// the ID should be obtained from that tenant too
+		tokenTenantInfo, err := d.client.WithTenant("me").Tenant(ctx, "")
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Cannot get tenant info",
+				fmt.Sprintf("Unable to get tenant info of the token: %v", err.Error()),
+			)
+			return
+		}
+		if tokenTenantInfo.TenantID != tenantInfo.TenantID {
+			resp.Diagnostics.AddError(
+				"Cannot query tenant",
+				fmt.Sprintf("Queries run as the token's tenant %s, but the provider manages tenant %s. "+
+					"Use a token of tenant %s to query its databases.", tokenTenantInfo.TenantID, tenantInfo.TenantID, tenantInfo.TenantID),
+			)
+			return
+		}
+	}
+
+	region := data.Region.ValueString()
+	if region == "" {
+		region = tenantInfo.HomeRegion
+	}
+	database := data.Database.ValueString()
+	maxRows := int64(defaultQueryMaxRows)
+	if !data.MaxRows.IsNull() {
+		maxRows = data.MaxRows.ValueInt64()
+	}
+
+	result, err := d.client.Query(ctx, region, database, data.SQL.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Cannot run query",
+			fmt.Sprintf("Unable to query database %q in region %s: %v", database, region, err.Error()),
+		)
+		return
+	}
+	defer result.Close()
+
+	rows := []string{}
+	for {
+		var row json.RawMessage
+		err = result.Next(&row)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Cannot read query result",
+				fmt.Sprintf("Unable to read the result of the query on database %q in region %s: %v", database, region, err.Error()),
+			)
+			return
+		}
+		if int64(len(rows)) >= maxRows {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_rows"),
+				"Too many rows",
+				fmt.Sprintf("The query returned more than %d rows. Increase max_rows or add a LIMIT clause to the query.", maxRows),
+			)
+			return
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, row); err != nil {
+			resp.Diagnostics.AddError(
+				"Cannot read query result",
+				fmt.Sprintf("Unable to read the result of the query on database %q in region %s: %v", database, region, err.Error()),
+			)
+			return
+		}
+		rows = append(rows, compact.String())
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%s/%s/%s", tenantInfo.TenantID, region, database))
+	data.Region = types.StringValue(region)
+	data.Rows = rows
+	data.RowCount = types.Int64Value(int64(len(rows)))
+	data.BytesScanned = types.Int64Value(int64(result.BytesScanned()))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
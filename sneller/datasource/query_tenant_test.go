@@ -0,0 +1,82 @@
+package datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/snellertest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestQueryTenant(t *testing.T) {
+	srv := snellertest.NewServer()
+	defer srv.Close()
+	srv.SetQueryResult("db", "SELECT 1", 0, map[string]any{"x": 1})
+
+	// another tenant that can be managed, but not queried
+	// using the token
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tenant/TOTHERTENANT" {
+			w.Write([]byte(`{"tenantID":"TOTHERTENANT","homeRegion":"` + srv.HomeRegion + `"}`))
+			return
+		}
+		srv.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer other.Close()
+
+	apiURL, err := url.Parse(other.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	d := &queryDataSource{}
+	var schemaResp datasource.SchemaResponse
+	d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	attrs := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		attrs[name] = tftypes.NewValue(attrType, nil)
+	}
+	attrs["database"] = tftypes.NewValue(tftypes.String, "db")
+	attrs["sql"] = tftypes.NewValue(tftypes.String, "SELECT 1")
+	config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, attrs)}
+
+	// the provider's tenant is the token's tenant
+	for _, tenantID := range []string{"", srv.TenantID} {
+		d.client = &api.Client{
+			Token:         srv.Token,
+			TenantID:      tenantID,
+			DefaultRegion: api.DefaultSnellerRegion,
+			ApiURL:        apiURL,
+			QueryURL:      apiURL,
+		}
+		resp := datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw}}
+		d.Read(ctx, datasource.ReadRequest{Config: config}, &resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics %v", resp.Diagnostics)
+		}
+		var data queryDataSourceModel
+		resp.Diagnostics.Append(resp.State.Get(ctx, &data)...)
+		expected := types.StringValue(srv.TenantID + "/" + srv.HomeRegion + "/db")
+		if !data.ID.Equal(expected) || !data.Region.Equal(types.StringValue(srv.HomeRegion)) {
+			t.Errorf("unexpected ID %s (region %s)", data.ID, data.Region)
+		}
+	}
+
+	// queries of another tenant fail
+	d.client = d.client.WithTenant("TOTHERTENANT")
+	resp := datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: config.Raw}}
+	d.Read(ctx, datasource.ReadRequest{Config: config}, &resp)
+	if !resp.Diagnostics.HasError() || !strings.Contains(resp.Diagnostics.Errors()[0].Detail(), "TOTHERTENANT") {
+		t.Errorf("expected error for another tenant, got %v", resp.Diagnostics)
+	}
+}
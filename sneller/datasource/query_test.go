@@ -0,0 +1,70 @@
+package datasource_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-sneller/sneller/acctest"
+	"terraform-provider-sneller/sneller/api"
+)
+
+func TestAccDataSourceQuery(t *testing.T) {
+	resourceName := "data.sneller_query.test"
+	const sql = `SELECT COUNT(*) AS "count" FROM "table-x"`
+	if acctest.FakeServer != nil {
+		acctest.FakeServer.SetQueryResult("testdb", sql, 0, map[string]any{"count": 0})
+	}
+	baseConfig := acctest.ProviderConfig + `
+		resource "sneller_tenant_region" "test" {
+			region   = "` + api.DefaultSnellerRegion + `"
+			bucket   = "` + acctest.Bucket1Name + `"
+			role_arn = "` + acctest.Role1ARN + `"
+		}
+
+		resource "sneller_table" "test" {
+			region   = sneller_tenant_region.test.region
+			database = "testdb"
+			table    = "table-x"
+			inputs   = [{
+				pattern = "s3://` + acctest.Bucket1Name + `/*.ndjson"
+				format  = "json"
+			}]
+		}`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create resource
+			{
+				Config: baseConfig,
+			},
+			// Read testing
+			{
+				Config: baseConfig + `
+					data "sneller_query" "test" {
+						region   = sneller_table.test.region
+						database = sneller_table.test.database
+						sql      = "SELECT COUNT(*) AS \"count\" FROM \"${sneller_table.test.table}\""
+					}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "region", api.DefaultSnellerRegion),
+					resource.TestCheckResourceAttr(resourceName, "row_count", "1"),
+					resource.TestCheckResourceAttr(resourceName, "rows.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "rows.0", `{"count":0}`),
+				),
+			},
+			// Read testing (home region)
+			{
+				Config: baseConfig + `
+					data "sneller_query" "test" {
+						database = sneller_table.test.database
+						sql      = "SELECT COUNT(*) AS \"count\" FROM \"${sneller_table.test.table}\""
+					}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet(resourceName, "region"),
+					resource.TestCheckResourceAttr(resourceName, "row_count", "1"),
+				),
+			},
+		},
+	})
+}
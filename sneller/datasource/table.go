@@ -3,8 +3,10 @@ package datasource
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 	"terraform-provider-sneller/sneller/model"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -27,10 +29,10 @@ type tableDataSource struct {
 }
 
 type tableDataSourceModel struct {
-	ID              types.String                `tfsdk:"id" json:"ignore"`
-	Region          types.String                `tfsdk:"region" json:"ignore"`
-	Database        types.String                `tfsdk:"database" json:"ignore"`
-	Location        types.String                `tfsdk:"location" json:"ignore"`
+	ID              types.String                `tfsdk:"id" json:"-"`
+	Region          types.String                `tfsdk:"region" json:"-"`
+	Database        types.String                `tfsdk:"database" json:"-"`
+	Location        types.String                `tfsdk:"location" json:"-"`
 	Table           *string                     `tfsdk:"table" json:"name"`
 	Inputs          []model.TableInputModel     `tfsdk:"inputs" json:"input"`
 	Partitions      []model.TablePartitionModel `tfsdk:"partitions" json:"partitions,omitempty"`
@@ -146,9 +148,15 @@ func (d *tableDataSource) Schema(ctx context.Context, req datasource.SchemaReque
 							Computed:    true,
 							NestedObject: schema.NestedAttributeObject{
 								Attributes: map[string]schema.Attribute{
+									"path": schema.StringAttribute{
+										Description: "Path of the field (use dots to specify a subfield).",
+										Computed:    true,
+									},
 									"field": schema.StringAttribute{
-										Description: "Field name.",
-										Required:    true,
+										Description:         "Field name. It is deprecated, use 'path' instead.",
+										MarkdownDescription: "Field name. It is deprecated, use `path` instead.",
+										DeprecationMessage:  "Use path instead.",
+										Required:            true,
 									},
 									"hints": schema.ListAttribute{
 										Description: "Hints.",
@@ -241,6 +249,9 @@ func (d *tableDataSource) Configure(ctx context.Context, req datasource.Configur
 }
 
 func (d *tableDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !providerdata.CheckClient(d.client, &resp.Diagnostics) {
+		return
+	}
 
 	var data tableDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -263,9 +274,9 @@ func (d *tableDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 	}
 
 	database, table := data.Database.ValueString(), *data.Table
-	tableDescription, err := d.client.Table(ctx, region, database, table)
+	tableDescription, _, err := d.client.Table(ctx, region, database, table)
 	if err != nil {
-		if err == api.ErrNotFound {
+		if errors.Is(err, api.ErrNotFound) {
 			resp.Diagnostics.AddError(
 				"Table not found",
 				fmt.Sprintf("Table %q not found in database %q (region %s)", table, database, region),
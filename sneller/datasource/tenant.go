@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -93,6 +94,9 @@ func (d *tenantDataSource) Configure(ctx context.Context, req datasource.Configu
 }
 
 func (d *tenantDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !providerdata.CheckClient(d.client, &resp.Diagnostics) {
+		return
+	}
 
 	var data tenantDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
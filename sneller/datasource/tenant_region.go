@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -94,6 +95,9 @@ func (d *tenantRegionDataSource) Configure(ctx context.Context, req datasource.C
 }
 
 func (d *tenantRegionDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !providerdata.CheckClient(d.client, &resp.Diagnostics) {
+		return
+	}
 
 	var data tenantRegionDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
@@ -79,6 +80,9 @@ func (d *usersDataSource) Configure(ctx context.Context, req datasource.Configur
 }
 
 func (d *usersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if !providerdata.CheckClient(d.client, &resp.Diagnostics) {
+		return
+	}
 
 	var data usersDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
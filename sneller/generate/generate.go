@@ -0,0 +1,324 @@
+// Package generate implements the `generate` command of the provider
+// binary, which exports the resources of an existing tenant as
+// Terraform configuration.
+package generate
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/provider"
+
+	tpf_provider "github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Run runs the `generate` command with the given arguments. The
+// provider is configured like it is by Terraform with an empty
+// provider block, so the token, region and endpoint are obtained
+// from the environment or the Sneller profile.
+func Run(ctx context.Context, version string, args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("dir", ".", "directory where the configuration files are written")
+	force := flags.Bool("force", false, "overwrite existing configuration files")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: terraform-provider-sneller generate [-dir <directory>] [-force]\n\n")
+		fmt.Fprintf(stderr, "Writes the tenant regions, tables, users and elastic proxy configurations\n")
+		fmt.Fprintf(stderr, "of the tenant as resources with import blocks (one file per resource type).\n\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	g, err := newGenerator(ctx, provider.New(version))
+	if err != nil {
+		return err
+	}
+	files, err := g.generate(ctx)
+	if err != nil {
+		return err
+	}
+	return writeFiles(*dir, files, *force, stderr)
+}
+
+// generator reads the resources of a tenant using the provider.
+type generator struct {
+	client  *api.Client
+	server  tfprotov6.ProviderServer
+	schemas map[string]*tfprotov6.Schema
+	files   map[string]*file
+}
+
+// newGenerator configures the provider and returns a generator. The
+// client is used to list the resources and the resources are read
+// using the provider's protocol server, so the state is exactly
+// the state that Terraform obtains when the resources are imported.
+// The provider is configured once and the generator uses the same
+// client as the resources.
+func newGenerator(ctx context.Context, newProvider func() tpf_provider.Provider) (*generator, error) {
+	p := &clientProvider{Provider: newProvider()}
+	server := providerserver.NewProtocol6(p)()
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get provider schema: %w", err)
+	}
+	if err := protoDiagsError(schemas.Diagnostics); err != nil {
+		return nil, fmt.Errorf("cannot get provider schema: %w", err)
+	}
+	configType := schemas.Provider.ValueType()
+	config := tftypes.NewValue(configType, nullAttributes(configType))
+	dynamicConfig, err := tfprotov6.NewDynamicValue(configType, config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode provider configuration: %w", err)
+	}
+	configured, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &dynamicConfig})
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure provider: %w", err)
+	}
+	if err := protoDiagsError(configured.Diagnostics); err != nil {
+		return nil, fmt.Errorf("cannot configure provider: %w", err)
+	}
+	if p.client == nil {
+		return nil, errors.New("cannot configure provider: no Sneller API client")
+	}
+
+	return &generator{
+		client:  p.client,
+		server:  server,
+		schemas: schemas.ResourceSchemas,
+		files:   make(map[string]*file),
+	}, nil
+}
+
+// clientProvider keeps the client of the configured provider.
+type clientProvider struct {
+	tpf_provider.Provider
+	client *api.Client
+}
+
+func (p *clientProvider) Configure(ctx context.Context, req tpf_provider.ConfigureRequest, resp *tpf_provider.ConfigureResponse) {
+	p.Provider.Configure(ctx, req, resp)
+	p.client, _ = resp.ResourceData.(*api.Client)
+}
+
+// generate reads all resources of the tenant and returns the
+// configuration files by name.
+func (g *generator) generate(ctx context.Context) (map[string][]byte, error) {
+	tenantInfo, err := g.client.Tenant(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get tenant info: %w", err)
+	}
+	tenantID := tenantInfo.TenantID
+
+	regions := make([]string, 0, len(tenantInfo.Regions))
+	for region, regionInfo := range tenantInfo.Regions {
+		if regionInfo.Bucket == "" {
+			// regions without a bucket aren't configured
+			continue
+		}
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		err := g.add(ctx, "sneller_tenant_region", region, fmt.Sprintf("%s/%s", tenantID, region))
+		if err != nil {
+			return nil, err
+		}
+
+		databases, err := g.client.Databases(ctx, region)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get databases in region %s: %w", region, err)
+		}
+		sort.Strings(databases)
+		for _, database := range databases {
+			tables, err := g.client.Database(ctx, region, database)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get tables of database %s in region %s: %w", database, region, err)
+			}
+			sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+			for _, table := range tables {
+				if !table.HasDefinition {
+					// tables without definition are only indexed
+					continue
+				}
+				name := database + "_" + table.Name
+				id := fmt.Sprintf("%s/%s/%s/%s", tenantID, region, database, table.Name)
+				if err := g.add(ctx, "sneller_table", name, id); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		_, _, err = g.client.ElasticProxyConfig(ctx, region)
+		if errors.Is(err, api.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to get elastic-proxy configuration in region %s: %w", region, err)
+		}
+		err = g.add(ctx, "sneller_elastic_proxy", region, fmt.Sprintf("%s/%s", tenantID, region))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	users, err := g.client.Users(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get users: %w", err)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+	for _, user := range users {
+		if strings.EqualFold(user.Email, tenantInfo.Email) {
+			// the tenant owner can't be managed, because
+			// destroying it would lock out the tenant
+			continue
+		}
+		name, _, _ := strings.Cut(user.Email, "@")
+		id := fmt.Sprintf("%s/%s", tenantID, user.UserID)
+		if err := g.add(ctx, "sneller_user", name, id); err != nil {
+			return nil, err
+		}
+	}
+
+	files := make(map[string][]byte, len(g.files))
+	for typeName, f := range g.files {
+		files[typeName+".tf"] = f.Bytes()
+	}
+	return files, nil
+}
+
+// add imports and reads the resource with the given identifier and
+// adds the resource to the configuration file of its type.
+func (g *generator) add(ctx context.Context, typeName, name, id string) error {
+	schema := g.schemas[typeName]
+	if schema == nil {
+		return fmt.Errorf("unknown resource type %s", typeName)
+	}
+	state, err := g.read(ctx, typeName, schema, id)
+	if err != nil {
+		return fmt.Errorf("unable to read %s %q: %w", typeName, id, err)
+	}
+	if state.IsNull() {
+		// the resource was removed while it was read
+		return nil
+	}
+
+	f := g.files[typeName]
+	if f == nil {
+		f = newFile(typeName)
+		g.files[typeName] = f
+	}
+	if err := f.add(name, id, schema.Block, state); err != nil {
+		return fmt.Errorf("unable to generate %s %q: %w", typeName, id, err)
+	}
+	return nil
+}
+
+// read imports the resource with the given identifier and returns
+// its refreshed state, like Terraform does for an import block.
+func (g *generator) read(ctx context.Context, typeName string, schema *tfprotov6.Schema, id string) (tftypes.Value, error) {
+	imported, err := g.server.ImportResourceState(ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		return tftypes.Value{}, err
+	}
+	if err := protoDiagsError(imported.Diagnostics); err != nil {
+		return tftypes.Value{}, err
+	}
+	if len(imported.ImportedResources) != 1 {
+		return tftypes.Value{}, fmt.Errorf("expected a single imported resource, got %d", len(imported.ImportedResources))
+	}
+
+	resp, err := g.server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     typeName,
+		CurrentState: imported.ImportedResources[0].State,
+		Private:      imported.ImportedResources[0].Private,
+	})
+	if err != nil {
+		return tftypes.Value{}, err
+	}
+	if err := protoDiagsError(resp.Diagnostics); err != nil {
+		return tftypes.Value{}, err
+	}
+	if resp.NewState == nil {
+		return tftypes.NewValue(schema.ValueType(), nil), nil
+	}
+	return resp.NewState.Unmarshal(schema.ValueType())
+}
+
+// writeFiles writes the configuration files to the directory. It
+// doesn't write any file when one of them exists already, unless
+// the files should be overwritten.
+func writeFiles(dir string, files map[string][]byte, force bool, stderr io.Writer) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !force {
+		for _, name := range names {
+			filename := filepath.Join(dir, name)
+			if _, err := os.Stat(filename); err == nil {
+				return fmt.Errorf("%s already exists (use -force to overwrite)", filename)
+			}
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, name := range names {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, files[name], 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "Wrote %s\n", filename)
+	}
+	if len(names) == 0 {
+		fmt.Fprintf(stderr, "No resources found\n")
+	}
+	return nil
+}
+
+// nullAttributes returns null values for all attributes of an
+// object type.
+func nullAttributes(t tftypes.Type) map[string]tftypes.Value {
+	objectType := t.(tftypes.Object)
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+	return values
+}
+
+// protoDiagsError returns an error for error diagnostics of the
+// provider protocol (or nil).
+func protoDiagsError(diags []*tfprotov6.Diagnostic) error {
+	var errs []error
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			errs = append(errs, fmt.Errorf("%s: %s", d.Summary, d.Detail))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,202 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/snellertest"
+	"terraform-provider-sneller/sneller/validate"
+	"testing"
+)
+
+// testServer returns a fake Sneller API with a configured home
+// region and a client for it. The provider is configured for the
+// fake API using the environment.
+func testServer(t *testing.T) (*snellertest.Server, *api.Client) {
+	srv := snellertest.NewServer()
+	t.Cleanup(srv.Close)
+
+	t.Setenv(api.EnvSnellerToken, srv.Token)
+	t.Setenv(api.EnvSnellerApiEndpoint, srv.URL)
+	t.Setenv(api.EnvSnellerRegion, srv.HomeRegion)
+	t.Setenv(api.EnvSnellerTenantID, "")
+	t.Setenv(api.EnvSnellerProfile, "")
+	t.Setenv(api.EnvSnellerConfigFile, filepath.Join(t.TempDir(), "config"))
+
+	apiURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &api.Client{
+		Client:        http.DefaultClient,
+		Token:         srv.Token,
+		DefaultRegion: srv.HomeRegion,
+		ApiURL:        apiURL,
+	}
+	return srv, client
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	srv, client := testServer(t)
+
+	region := srv.HomeRegion
+	if err := client.SetBucket(ctx, region, "s3://sneller-cache", "arn:aws:iam::111111111111:role/sneller"); err != nil {
+		t.Fatal(err)
+	}
+	definition := `{
+		"input": [{"pattern": "s3://source/logs/{date}/*.json", "format": "json", "hints": [{"path": "ts", "hints": ["datetime"]}]}],
+		"partitions": [{"field": "date"}],
+		"retention_policy": {"field": "ts", "valid_for": "30d"},
+		"skip_backfill": true
+	}`
+	if _, err := client.SetTable(ctx, region, "logs", "web-access", []byte(definition), ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateUser(ctx, "jane.doe@example.com", false, nil, ptr("Jane"), ptr("Doe")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateUser(ctx, "jane.doe@example.org", true, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.SetElasticProxyConfig(ctx, region, api.ElasticProxyConfig{
+		Mapping: map[string]api.ElasticProxyMappingConfig{
+			"web": {Database: "logs", Table: "web-access", IgnoreTotalHits: true},
+		},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	var stderr bytes.Buffer
+	if err := Run(ctx, "test", []string{"-dir", dir}, &stderr); err != nil {
+		t.Fatalf("generate failed: %v\n%s", err, stderr.String())
+	}
+
+	for name, want := range map[string]string{
+		"sneller_tenant_region.tf": `resource "sneller_tenant_region" "us_east_1" {
+  bucket   = "sneller-cache"
+  role_arn = "arn:aws:iam::111111111111:role/sneller"
+  region   = "us-east-1"
+}
+
+import {
+  to = sneller_tenant_region.us_east_1
+  id = "TFAKETENANT01/us-east-1"
+}
+`,
+		"sneller_table.tf": `resource "sneller_table" "logs_web_access" {
+  database = "logs"
+  table    = "web-access"
+  inputs = [{
+    format = "json"
+    json_hints = [{
+      hints = ["datetime"]
+      path  = "ts"
+    }]
+    pattern = "s3://source/logs/{date}/*.json"
+  }]
+  partitions = [{
+    field = "date"
+  }]
+  region = "us-east-1"
+  retention_policy = {
+    field     = "ts"
+    valid_for = "30d"
+  }
+  skip_backfill = true
+}
+
+import {
+  to = sneller_table.logs_web_access
+  id = "TFAKETENANT01/us-east-1/logs/web-access"
+}
+`,
+		"sneller_elastic_proxy.tf": `resource "sneller_elastic_proxy" "us_east_1" {
+  index = {
+    web = {
+      database                   = "logs"
+      ignore_sum_other_doc_count = false
+      ignore_total_hits          = true
+      table                      = "web-access"
+    }
+  }
+  log_path = ""
+  region   = "us-east-1"
+}
+
+import {
+  to = sneller_elastic_proxy.us_east_1
+  id = "TFAKETENANT01/us-east-1"
+}
+`,
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("unexpected %s:\n%s\nexpected:\n%s", name, got, want)
+		}
+	}
+
+	// the generated tables pass the validation of the provider
+	var stdout bytes.Buffer
+	if err := validate.Run(ctx, "test", []string{filepath.Join(dir, "sneller_table.tf")}, &stdout, &stderr); err != nil || stdout.Len() > 0 {
+		t.Errorf("generated tables are invalid: %v\n%s", err, stdout.String())
+	}
+
+	// users with the same name get a unique resource name
+	users, err := os.ReadFile(filepath.Join(dir, "sneller_user.tf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`resource "sneller_user" "jane_doe" {`,
+		`resource "sneller_user" "jane_doe_2" {`,
+		`given_name  = "Jane"`,
+		`to = sneller_user.jane_doe_2`,
+	} {
+		if !strings.Contains(string(users), want) {
+			t.Errorf("expected %q in sneller_user.tf:\n%s", want, users)
+		}
+	}
+
+	// the tenant owner isn't exported
+	if strings.Contains(string(users), "owner@example.com") {
+		t.Errorf("unexpected tenant owner in sneller_user.tf:\n%s", users)
+	}
+
+	// existing files are only overwritten when forced
+	err = Run(ctx, "test", []string{"-dir", dir}, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected error for existing files, got %v", err)
+	}
+	if err := Run(ctx, "test", []string{"-dir", dir, "-force"}, &stderr); err != nil {
+		t.Errorf("generate failed: %v", err)
+	}
+}
+
+func TestResourceName(t *testing.T) {
+	for name, want := range map[string]string{
+		"us-east-1":       "us_east_1",
+		"logs_web-access": "logs_web_access",
+		"Jane.Doe+test":   "jane_doe_test",
+		"2023_events":     "_2023_events",
+		"":                "_",
+	} {
+		if got := resourceName(name); got != want {
+			t.Errorf("resourceName(%q) = %q, expected %q", name, got, want)
+		}
+	}
+}
+
+func ptr[T any](t T) *T {
+	return &t
+}
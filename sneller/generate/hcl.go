@@ -0,0 +1,315 @@
+package generate
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/exp/slices"
+)
+
+// omittedAttributes aren't written, because they are set when the
+// resource is imported (the tenant is part of the identifier).
+var omittedAttributes = map[string]bool{
+	"tenant_id": true,
+}
+
+// file is a generated configuration file with the resources of a
+// single type. Each resource is followed by its import block.
+type file struct {
+	typeName string
+	f        *hclwrite.File
+	names    map[string]bool
+}
+
+func newFile(typeName string) *file {
+	return &file{
+		typeName: typeName,
+		f:        hclwrite.NewEmptyFile(),
+		names:    make(map[string]bool),
+	}
+}
+
+// add adds a resource and its import block. The name is turned into
+// a valid resource name that is unique within the file.
+func (f *file) add(name, id string, block *tfprotov6.SchemaBlock, state tftypes.Value) error {
+	name = f.uniqueName(resourceName(name))
+
+	body := f.f.Body()
+	if len(body.Blocks()) > 0 {
+		body.AppendNewline()
+	}
+	resource := body.AppendNewBlock("resource", []string{f.typeName, name})
+	if err := writeBody(resource.Body(), block, state, omittedAttributes); err != nil {
+		return err
+	}
+
+	body.AppendNewline()
+	importBody := body.AppendNewBlock("import", nil).Body()
+	importBody.SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: f.typeName},
+		hcl.TraverseAttr{Name: name},
+	})
+	importBody.SetAttributeValue("id", cty.StringVal(id))
+	return nil
+}
+
+// Bytes returns the formatted contents of the file.
+func (f *file) Bytes() []byte {
+	return hclwrite.Format(f.f.Bytes())
+}
+
+// uniqueName appends a suffix to names that are already used.
+func (f *file) uniqueName(name string) string {
+	unique := name
+	for i := 2; f.names[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	f.names[unique] = true
+	return unique
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// resourceName returns a valid resource name that resembles the
+// given name (i.e. the database and table name).
+func resourceName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// writeBody writes the configurable attributes and nested blocks of
+// a value to the body. Null values aren't written, because they
+// are equivalent to attributes that aren't set.
+func writeBody(body *hclwrite.Body, block *tfprotov6.SchemaBlock, value tftypes.Value, omit map[string]bool) error {
+	var values map[string]tftypes.Value
+	if err := value.As(&values); err != nil {
+		return err
+	}
+
+	// required attributes are written first
+	attributes := slices.Clone(block.Attributes)
+	sort.SliceStable(attributes, func(i, j int) bool {
+		if attributes[i].Required != attributes[j].Required {
+			return attributes[i].Required
+		}
+		return attributes[i].Name < attributes[j].Name
+	})
+	for _, attribute := range attributes {
+		v := values[attribute.Name]
+		if !configurable(attribute) || omit[attribute.Name] || v.IsNull() {
+			continue
+		}
+		cv, err := attributeValue(attribute, v)
+		if err != nil {
+			return fmt.Errorf("attribute %s: %w", attribute.Name, err)
+		}
+		body.SetAttributeValue(attribute.Name, cv)
+	}
+
+	blockTypes := slices.Clone(block.BlockTypes)
+	sort.Slice(blockTypes, func(i, j int) bool { return blockTypes[i].TypeName < blockTypes[j].TypeName })
+	for _, blockType := range blockTypes {
+		v := values[blockType.TypeName]
+		if omit[blockType.TypeName] || v.IsNull() {
+			continue
+		}
+		switch blockType.Nesting {
+		case tfprotov6.SchemaNestedBlockNestingModeSingle, tfprotov6.SchemaNestedBlockNestingModeGroup:
+			err := writeBody(body.AppendNewBlock(blockType.TypeName, nil).Body(), blockType.Block, v, nil)
+			if err != nil {
+				return fmt.Errorf("block %s: %w", blockType.TypeName, err)
+			}
+		case tfprotov6.SchemaNestedBlockNestingModeList, tfprotov6.SchemaNestedBlockNestingModeSet:
+			var elements []tftypes.Value
+			if err := v.As(&elements); err != nil {
+				return fmt.Errorf("block %s: %w", blockType.TypeName, err)
+			}
+			for _, element := range elements {
+				err := writeBody(body.AppendNewBlock(blockType.TypeName, nil).Body(), blockType.Block, element, nil)
+				if err != nil {
+					return fmt.Errorf("block %s: %w", blockType.TypeName, err)
+				}
+			}
+		case tfprotov6.SchemaNestedBlockNestingModeMap:
+			var elements map[string]tftypes.Value
+			if err := v.As(&elements); err != nil {
+				return fmt.Errorf("block %s: %w", blockType.TypeName, err)
+			}
+			keys := make([]string, 0, len(elements))
+			for key := range elements {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				err := writeBody(body.AppendNewBlock(blockType.TypeName, []string{key}).Body(), blockType.Block, elements[key], nil)
+				if err != nil {
+					return fmt.Errorf("block %s: %w", blockType.TypeName, err)
+				}
+			}
+		default:
+			return fmt.Errorf("block %s: unsupported nesting mode %s", blockType.TypeName, blockType.Nesting)
+		}
+	}
+	return nil
+}
+
+// configurable returns whether the attribute can be set in the
+// configuration. Deprecated attributes are skipped, because their
+// replacement is set as well.
+func configurable(attribute *tfprotov6.SchemaAttribute) bool {
+	return (attribute.Required || attribute.Optional) && !attribute.Deprecated
+}
+
+// attributeValue converts the value of an attribute. Nested
+// attributes only contain their configurable attributes.
+func attributeValue(attribute *tfprotov6.SchemaAttribute, value tftypes.Value) (cty.Value, error) {
+	if attribute.NestedType == nil {
+		return ctyValue(value)
+	}
+
+	switch attribute.NestedType.Nesting {
+	case tfprotov6.SchemaObjectNestingModeSingle:
+		return objectValue(attribute.NestedType.Attributes, value)
+	case tfprotov6.SchemaObjectNestingModeList, tfprotov6.SchemaObjectNestingModeSet:
+		var elements []tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return cty.NilVal, err
+		}
+		values := make([]cty.Value, len(elements))
+		for i, element := range elements {
+			v, err := objectValue(attribute.NestedType.Attributes, element)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			values[i] = v
+		}
+		return tupleVal(values), nil
+	case tfprotov6.SchemaObjectNestingModeMap:
+		var elements map[string]tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return cty.NilVal, err
+		}
+		values := make(map[string]cty.Value, len(elements))
+		for key, element := range elements {
+			v, err := objectValue(attribute.NestedType.Attributes, element)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			values[key] = v
+		}
+		return objectVal(values), nil
+	}
+	return cty.NilVal, fmt.Errorf("unsupported nesting mode %s", attribute.NestedType.Nesting)
+}
+
+// objectValue converts a nested object with the given attributes.
+func objectValue(attributes []*tfprotov6.SchemaAttribute, value tftypes.Value) (cty.Value, error) {
+	if value.IsNull() {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+	var elements map[string]tftypes.Value
+	if err := value.As(&elements); err != nil {
+		return cty.NilVal, err
+	}
+	values := make(map[string]cty.Value, len(attributes))
+	for _, attribute := range attributes {
+		v := elements[attribute.Name]
+		if !configurable(attribute) || v.IsNull() {
+			continue
+		}
+		cv, err := attributeValue(attribute, v)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("attribute %s: %w", attribute.Name, err)
+		}
+		values[attribute.Name] = cv
+	}
+	return objectVal(values), nil
+}
+
+// ctyValue converts a value without nested attributes. Collections
+// are converted to tuples and objects, so the elements don't need
+// to have the same type.
+func ctyValue(value tftypes.Value) (cty.Value, error) {
+	if !value.IsKnown() {
+		return cty.NilVal, fmt.Errorf("unknown value")
+	}
+	if value.IsNull() {
+		return cty.NullVal(cty.DynamicPseudoType), nil
+	}
+
+	typ := value.Type()
+	switch {
+	case typ.Is(tftypes.String):
+		var s string
+		if err := value.As(&s); err != nil {
+			return cty.NilVal, err
+		}
+		return cty.StringVal(s), nil
+	case typ.Is(tftypes.Number):
+		n := new(big.Float)
+		if err := value.As(&n); err != nil {
+			return cty.NilVal, err
+		}
+		return cty.NumberVal(n), nil
+	case typ.Is(tftypes.Bool):
+		var b bool
+		if err := value.As(&b); err != nil {
+			return cty.NilVal, err
+		}
+		return cty.BoolVal(b), nil
+	case typ.Is(tftypes.List{}), typ.Is(tftypes.Set{}), typ.Is(tftypes.Tuple{}):
+		var elements []tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return cty.NilVal, err
+		}
+		values := make([]cty.Value, len(elements))
+		for i, element := range elements {
+			v, err := ctyValue(element)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			values[i] = v
+		}
+		return tupleVal(values), nil
+	case typ.Is(tftypes.Map{}), typ.Is(tftypes.Object{}):
+		var elements map[string]tftypes.Value
+		if err := value.As(&elements); err != nil {
+			return cty.NilVal, err
+		}
+		values := make(map[string]cty.Value, len(elements))
+		for key, element := range elements {
+			v, err := ctyValue(element)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			values[key] = v
+		}
+		return objectVal(values), nil
+	}
+	return cty.NilVal, fmt.Errorf("unsupported type %s", typ)
+}
+
+func tupleVal(values []cty.Value) cty.Value {
+	if len(values) == 0 {
+		return cty.EmptyTupleVal
+	}
+	return cty.TupleVal(values)
+}
+
+func objectVal(values map[string]cty.Value) cty.Value {
+	if len(values) == 0 {
+		return cty.EmptyObjectVal
+	}
+	return cty.ObjectVal(values)
+}
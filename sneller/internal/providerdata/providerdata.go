@@ -0,0 +1,30 @@
+// Package providerdata holds the helpers for the data that the
+// provider passes to its resources and data sources.
+package providerdata
+
+import (
+	"terraform-provider-sneller/sneller/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// CheckClient adds an error diagnostic and returns false when the
+// provider isn't configured, because its configuration depends on
+// values that are only known during apply.
+//
+// Resources don't check the client when they're refreshed, but
+// keep the prior state instead. That way, the resources that the
+// provider configuration depends on can be planned and applied
+// first. Imports, data sources and all other operations need the
+// client and fail.
+func CheckClient(client *api.Client, diags *diag.Diagnostics) bool {
+	if client == nil {
+		diags.AddError(
+			"Unconfigured provider",
+			"The provider configuration depends on values that aren't known yet. "+
+				"Apply the resources that the provider configuration depends on first.",
+		)
+		return false
+	}
+	return true
+}
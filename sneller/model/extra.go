@@ -0,0 +1,126 @@
+package model
+
+import (
+	"encoding/json"
+
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// TableFields are the top-level fields of a table definition
+	// that are modeled by the provider.
+	TableFields = []string{"input", "partitions", "retention_policy", "beta_features", "skip_backfill"}
+
+	// TableInputFields are the fields of a table input that are
+	// modeled by the provider.
+	TableInputFields = []string{"pattern", "format", "hints"}
+)
+
+// TableExtraFields returns the fields of the table definition
+// that are not modeled by the provider, so they can be retained
+// when the definition is written again. The extra fields of the
+// inputs are returned in the `input` array, together with the
+// pattern that identifies the input. It returns nil if there
+// aren't any extra fields.
+func TableExtraFields(definition []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(definition, &fields); err != nil {
+		return nil, err
+	}
+
+	extra := make(map[string]any)
+	for key, value := range fields {
+		if !slices.Contains(TableFields, key) {
+			extra[key] = value
+		}
+	}
+
+	var inputs []map[string]json.RawMessage
+	if value, ok := fields["input"]; ok {
+		if err := json.Unmarshal(value, &inputs); err != nil {
+			return nil, err
+		}
+	}
+	var extraInputs []map[string]json.RawMessage
+	for _, input := range inputs {
+		extraInput := make(map[string]json.RawMessage)
+		for key, value := range input {
+			if !slices.Contains(TableInputFields, key) {
+				extraInput[key] = value
+			}
+		}
+		if len(extraInput) > 0 {
+			extraInput["pattern"] = input["pattern"]
+			extraInputs = append(extraInputs, extraInput)
+		}
+	}
+	if len(extraInputs) > 0 {
+		extra["input"] = extraInputs
+	}
+
+	if len(extra) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(extra)
+}
+
+// MergeTableExtraFields adds the extra fields (as returned by
+// TableExtraFields) to the table definition. Input fields are
+// matched using the input's pattern. Fields that are already
+// set in the definition are never overwritten.
+func MergeTableExtraFields(definition, extra []byte) ([]byte, error) {
+	if len(extra) == 0 {
+		return definition, nil
+	}
+
+	var fields, extraFields map[string]json.RawMessage
+	if err := json.Unmarshal(definition, &fields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(extra, &extraFields); err != nil {
+		return nil, err
+	}
+
+	for key, value := range extraFields {
+		if _, ok := fields[key]; !ok && key != "input" {
+			fields[key] = value
+		}
+	}
+
+	if value, ok := extraFields["input"]; ok && fields["input"] != nil {
+		var inputs, extraInputs []map[string]json.RawMessage
+		if err := json.Unmarshal(fields["input"], &inputs); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(value, &extraInputs); err != nil {
+			return nil, err
+		}
+		for _, input := range inputs {
+			for _, extraInput := range extraInputs {
+				if rawString(extraInput["pattern"]) != rawString(input["pattern"]) {
+					continue
+				}
+				for key, value := range extraInput {
+					if _, ok := input[key]; !ok {
+						input[key] = value
+					}
+				}
+			}
+		}
+		data, err := json.Marshal(inputs)
+		if err != nil {
+			return nil, err
+		}
+		fields["input"] = data
+	}
+
+	return json.Marshal(fields)
+}
+
+// rawString decodes a JSON string (it returns an empty string if
+// the value isn't a string).
+func rawString(value json.RawMessage) string {
+	var s string
+	json.Unmarshal(value, &s)
+	return s
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTableExtraFields(t *testing.T) {
+	definition := []byte(`{
+		"input": [
+			{"pattern": "s3://bucket/a/*.ndjson", "format": "json", "hints": [], "compression": "zstd"},
+			{"pattern": "s3://bucket/b/*.ndjson", "format": "json"}
+		],
+		"partitions": [{"field": "date"}],
+		"skip_backfill": true,
+		"update_delay": "5m",
+		"features": ["x"]
+	}`)
+
+	extra, err := TableExtraFields(definition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSONEqual(t, `{
+		"features": ["x"],
+		"input": [{"pattern": "s3://bucket/a/*.ndjson", "compression": "zstd"}],
+		"update_delay": "5m"
+	}`, extra)
+
+	extra, err = TableExtraFields([]byte(`{"input":[{"pattern":"s3://bucket/*.ndjson","format":"json"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extra != nil {
+		t.Errorf("expected no extra fields, got %s", extra)
+	}
+}
+
+func TestMergeTableExtraFields(t *testing.T) {
+	definition := []byte(`{
+		"input": [
+			{"pattern": "s3://bucket/b/*.ndjson", "format": "json"},
+			{"pattern": "s3://bucket/a/*.ndjson", "format": "json.gz"}
+		],
+		"update_delay": "1m"
+	}`)
+	extra := []byte(`{
+		"features": ["x"],
+		"input": [
+			{"pattern": "s3://bucket/a/*.ndjson", "compression": "zstd", "format": "json"},
+			{"pattern": "s3://bucket/removed/*.ndjson", "compression": "zstd"}
+		],
+		"update_delay": "5m"
+	}`)
+
+	merged, err := MergeTableExtraFields(definition, extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSONEqual(t, `{
+		"features": ["x"],
+		"input": [
+			{"pattern": "s3://bucket/b/*.ndjson", "format": "json"},
+			{"pattern": "s3://bucket/a/*.ndjson", "format": "json.gz", "compression": "zstd"}
+		],
+		"update_delay": "1m"
+	}`, merged)
+
+	merged, err = MergeTableExtraFields(definition, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(merged) != string(definition) {
+		t.Errorf("definition should be unchanged without extra fields")
+	}
+}
+
+func assertJSONEqual(t *testing.T, expected string, actual []byte) {
+	t.Helper()
+	var e, a any
+	if err := json.Unmarshal([]byte(expected), &e); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(actual, &a); err != nil {
+		t.Fatalf("invalid JSON %s: %v", actual, err)
+	}
+	if !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
@@ -0,0 +1,130 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// JSONType is a string type that holds a JSON object. Values
+// are semantically equal when they hold the same object, so
+// key ordering and whitespace don't cause differences.
+type JSONType struct {
+	basetypes.StringType
+}
+
+var (
+	_ basetypes.StringTypable = JSONType{}
+	_ xattr.TypeWithValidate  = JSONType{}
+)
+
+func (t JSONType) Equal(o attr.Type) bool {
+	_, ok := o.(JSONType)
+	return ok
+}
+
+func (t JSONType) String() string {
+	return "model.JSONType"
+}
+
+func (t JSONType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return JSONValue{StringValue: in}, nil
+}
+
+func (t JSONType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return JSONValue{StringValue: stringValue}, nil
+}
+
+func (t JSONType) ValueType(_ context.Context) attr.Value {
+	return JSONValue{}
+}
+
+func (t JSONType) Validate(_ context.Context, in tftypes.Value, p path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !in.IsKnown() || in.IsNull() {
+		return diags
+	}
+
+	var s string
+	if err := in.As(&s); err != nil {
+		diags.AddAttributeError(p, "Invalid JSON value", err.Error())
+		return diags
+	}
+	var object map[string]any
+	if err := json.Unmarshal([]byte(s), &object); err != nil {
+		diags.AddAttributeError(p, "Invalid JSON value", fmt.Sprintf("The value should be a valid JSON object: %v", err.Error()))
+	}
+	return diags
+}
+
+// JSONValue is the value of a JSONType.
+type JSONValue struct {
+	basetypes.StringValue
+}
+
+var _ basetypes.StringValuableWithSemanticEquals = JSONValue{}
+
+func NewJSONNull() JSONValue {
+	return JSONValue{StringValue: basetypes.NewStringNull()}
+}
+
+func NewJSONUnknown() JSONValue {
+	return JSONValue{StringValue: basetypes.NewStringUnknown()}
+}
+
+func NewJSONValue(value string) JSONValue {
+	return JSONValue{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v JSONValue) Type(_ context.Context) attr.Type {
+	return JSONType{}
+}
+
+func (v JSONValue) Equal(o attr.Value) bool {
+	other, ok := o.(JSONValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v JSONValue) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	newValue, ok := newValuable.(JSONValue)
+	if !ok {
+		diags.AddError(
+			"Semantic equality check error",
+			fmt.Sprintf("Expected value type %T, but got %T", v, newValuable),
+		)
+		return false, diags
+	}
+	return JSONEqual(v.ValueString(), newValue.ValueString()), diags
+}
+
+// JSONEqual returns true if both strings hold the same JSON value.
+func JSONEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var va, vb any
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
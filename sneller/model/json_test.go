@@ -0,0 +1,48 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestJSONSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{`{"a":1,"b":[1,2]}`, `{"b": [1, 2], "a": 1}`, true},
+		{"{\n  \"input\": []\n}", `{"input":[]}`, true},
+		{`{"a":1}`, `{"a":2}`, false},
+		{`{"b":[1,2]}`, `{"b":[2,1]}`, false},
+		{`{"a":1}`, `invalid`, false},
+	}
+	for _, tt := range tests {
+		equal, diags := NewJSONValue(tt.a).StringSemanticEquals(context.Background(), NewJSONValue(tt.b))
+		if diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		if equal != tt.equal {
+			t.Errorf("%s == %s: expected %v, got %v", tt.a, tt.b, tt.equal, equal)
+		}
+	}
+}
+
+func TestJSONValidate(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{`{"input":[]}`, true},
+		{`[1,2]`, false},
+		{`{"input":`, false},
+	}
+	for _, tt := range tests {
+		diags := JSONType{}.Validate(context.Background(), tftypes.NewValue(tftypes.String, tt.value), path.Root("definition_json"))
+		if diags.HasError() == tt.valid {
+			t.Errorf("%s: expected valid=%v, got %v", tt.value, tt.valid, diags)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"sort"
 	"strings"
+
+	"golang.org/x/exp/slices"
 )
 
 var (
@@ -128,6 +130,56 @@ func (m *TableInputModel) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// Normalize converts the input to the canonical form that is
+// used by the provider. The format is stored without a leading
+// dot and the optional flags of CSV/TSV fields are set to their
+// default values, so a definition that is read back from the
+// Sneller API doesn't differ from the configuration.
+func (m *TableInputModel) Normalize() {
+	if m.Format != nil {
+		format := CanonicalFormat(*m.Format)
+		m.Format = &format
+	}
+	if m.CSVHints != nil {
+		normalizeXSVFields(m.CSVHints.Fields)
+	}
+	if m.TSVHints != nil {
+		normalizeXSVFields(m.TSVHints.Fields)
+	}
+}
+
+// CanonicalTableInputs returns the canonical form of the inputs
+// (see Normalize) with sorted JSON hints. The inputs aren't
+// modified. The canonical form is used to compare definitions.
+func CanonicalTableInputs(inputs []TableInputModel) ([]TableInputModel, error) {
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, err
+	}
+	var canonical []TableInputModel
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return nil, err
+	}
+	for i := range canonical {
+		canonical[i].Normalize()
+		for j := range canonical[i].JSONHints {
+			canonical[i].JSONHints[j].Hints = canonical[i].JSONHints[j].Hints.Canonical()
+		}
+	}
+	return canonical, nil
+}
+
+func normalizeXSVFields(fields []TableInputXSVHintsFieldModel) {
+	for i := range fields {
+		if fields[i].AllowEmpty == nil {
+			fields[i].AllowEmpty = new(bool)
+		}
+		if fields[i].NoIndex == nil {
+			fields[i].NoIndex = new(bool)
+		}
+	}
+}
+
 type TableInputJSONHintsModel struct {
 	Rules []TableInputJSONHintModel
 }
@@ -175,6 +227,7 @@ func (h *TableInputJSONHintsModel) decodeRulesFromObject(d *json.Decoder) error
 
 		h.Rules = append(h.Rules, TableInputJSONHintModel{
 			Path:  path,
+			Field: &path,
 			Hints: hints,
 		})
 	}
@@ -199,6 +252,7 @@ func (h *TableInputJSONHintsModel) decodeRulesFromArray(d *json.Decoder) error {
 
 			h.Rules = append(h.Rules, TableInputJSONHintModel{
 				Path:  path,
+				Field: &path,
 				Hints: hints,
 			})
 
@@ -306,6 +360,13 @@ var errErrDelim = errors.New("invalid end of array or object")
 
 type Hints []string
 
+// Canonical returns a sorted copy of the hints.
+func (h Hints) Canonical() Hints {
+	c := slices.Clone(h)
+	sort.Strings(c)
+	return c
+}
+
 func (h *Hints) MarshallJSON() ([]byte, error) {
 	if len(*h) == 0 {
 		return json.Marshal("default")
@@ -341,8 +402,9 @@ func (h *Hints) UnmarshallJSON(data []byte) (err error) {
 }
 
 type TableInputJSONHintModel struct {
-	Path  string `tfsdk:"path"  json:"path"`
-	Hints Hints  `tfsdk:"hints" json:"hints"`
+	Path  string  `tfsdk:"path" json:"path"`
+	Field *string `tfsdk:"field" json:"-"` // deprecated alias of the path
+	Hints Hints   `tfsdk:"hints" json:"hints"`
 }
 
 type TableInputCSVHintModel struct {
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"golang.org/x/exp/slices"
+)
+
+// FormatType is a string type for input formats. The Sneller
+// API may return formats with a leading dot (i.e. `.json`), so
+// formats are semantically equal when they only differ in the
+// leading dot.
+type FormatType struct {
+	basetypes.StringType
+}
+
+var _ basetypes.StringTypable = FormatType{}
+
+func (t FormatType) Equal(o attr.Type) bool {
+	_, ok := o.(FormatType)
+	return ok
+}
+
+func (t FormatType) String() string {
+	return "model.FormatType"
+}
+
+func (t FormatType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return FormatValue{StringValue: in}, nil
+}
+
+func (t FormatType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return FormatValue{StringValue: stringValue}, nil
+}
+
+func (t FormatType) ValueType(_ context.Context) attr.Value {
+	return FormatValue{}
+}
+
+// FormatValue is the value of a FormatType.
+type FormatValue struct {
+	basetypes.StringValue
+}
+
+var _ basetypes.StringValuableWithSemanticEquals = FormatValue{}
+
+func (v FormatValue) Type(_ context.Context) attr.Type {
+	return FormatType{}
+}
+
+func (v FormatValue) Equal(o attr.Value) bool {
+	other, ok := o.(FormatValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v FormatValue) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	newValue, ok := newValuable.(FormatValue)
+	if !ok {
+		diags.AddError(
+			"Semantic equality check error",
+			fmt.Sprintf("Expected value type %T, but got %T", v, newValuable),
+		)
+		return false, diags
+	}
+	return CanonicalFormat(v.ValueString()) == CanonicalFormat(newValue.ValueString()), diags
+}
+
+// CanonicalFormat returns the format without a leading dot.
+func CanonicalFormat(format string) string {
+	return strings.TrimPrefix(format, ".")
+}
+
+// HintsType is a list of strings that holds ingestion hints.
+// The order of the hints is irrelevant (the Sneller API may
+// return them sorted), so hints are semantically equal when
+// they hold the same values.
+type HintsType struct {
+	basetypes.ListType
+}
+
+var _ basetypes.ListTypable = HintsType{}
+
+func NewHintsType() HintsType {
+	return HintsType{basetypes.ListType{ElemType: types.StringType}}
+}
+
+func (t HintsType) Equal(o attr.Type) bool {
+	other, ok := o.(HintsType)
+	if !ok {
+		return false
+	}
+	return t.ListType.Equal(other.ListType)
+}
+
+func (t HintsType) String() string {
+	return "model.HintsType"
+}
+
+func (t HintsType) ValueFromList(_ context.Context, in basetypes.ListValue) (basetypes.ListValuable, diag.Diagnostics) {
+	return HintsValue{ListValue: in}, nil
+}
+
+func (t HintsType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.ListType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	listValue, ok := attrValue.(basetypes.ListValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return HintsValue{ListValue: listValue}, nil
+}
+
+func (t HintsType) ValueType(_ context.Context) attr.Value {
+	return HintsValue{}
+}
+
+// HintsValue is the value of a HintsType.
+type HintsValue struct {
+	basetypes.ListValue
+}
+
+var _ basetypes.ListValuableWithSemanticEquals = HintsValue{}
+
+func (v HintsValue) Type(_ context.Context) attr.Type {
+	return NewHintsType()
+}
+
+func (v HintsValue) Equal(o attr.Value) bool {
+	other, ok := o.(HintsValue)
+	if !ok {
+		return false
+	}
+	return v.ListValue.Equal(other.ListValue)
+}
+
+func (v HintsValue) ListSemanticEquals(ctx context.Context, newValuable basetypes.ListValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	newValue, ok := newValuable.(HintsValue)
+	if !ok {
+		diags.AddError(
+			"Semantic equality check error",
+			fmt.Sprintf("Expected value type %T, but got %T", v, newValuable),
+		)
+		return false, diags
+	}
+
+	var oldHints, newHints Hints
+	diags.Append(v.ElementsAs(ctx, &oldHints, false)...)
+	diags.Append(newValue.ElementsAs(ctx, &newHints, false)...)
+	if diags.HasError() {
+		return false, diags
+	}
+	return slices.Equal(oldHints.Canonical(), newHints.Canonical()), diags
+}
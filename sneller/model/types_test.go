@@ -0,0 +1,147 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"terraform-provider-sneller/sneller/api"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestFormatSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"json", "json", true},
+		{".json", "json", true},
+		{"csv.gz", ".csv.gz", true},
+		{"json", "json.gz", false},
+	}
+	for _, tt := range tests {
+		a := FormatValue{StringValue: basetypes.NewStringValue(tt.a)}
+		b := FormatValue{StringValue: basetypes.NewStringValue(tt.b)}
+		equal, diags := a.StringSemanticEquals(context.Background(), b)
+		if diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		if equal != tt.equal {
+			t.Errorf("%s == %s: expected %v, got %v", tt.a, tt.b, tt.equal, equal)
+		}
+	}
+}
+
+func TestHintsSemanticEquals(t *testing.T) {
+	tests := []struct {
+		a, b  []string
+		equal bool
+	}{
+		{[]string{"int"}, []string{"int"}, true},
+		{[]string{"no_index", "datetime"}, []string{"datetime", "no_index"}, true},
+		{[]string{"int"}, []string{"int", "no_index"}, false},
+		{[]string{"int"}, []string{"string"}, false},
+	}
+	for _, tt := range tests {
+		a, b := hintsValue(t, tt.a), hintsValue(t, tt.b)
+		equal, diags := a.ListSemanticEquals(context.Background(), b)
+		if diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		if equal != tt.equal {
+			t.Errorf("%v == %v: expected %v, got %v", tt.a, tt.b, tt.equal, equal)
+		}
+	}
+}
+
+func hintsValue(t *testing.T, hints []string) HintsValue {
+	elements := make([]attr.Value, len(hints))
+	for i, hint := range hints {
+		elements[i] = types.StringValue(hint)
+	}
+	value, diags := basetypes.NewListValue(types.StringType, elements)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	return HintsValue{ListValue: value}
+}
+
+// TestCanonicalTableInputs compares the canonical form of the
+// inputs in testdata/canonical/<format>.json (as returned by the
+// Sneller API) with the golden file. Run with -update to update
+// the golden files.
+func TestCanonicalTableInputs(t *testing.T) {
+	for _, format := range api.Formats {
+		t.Run(format, func(t *testing.T) {
+			filename := filepath.Join("testdata", "canonical", format+".json")
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var inputs []TableInputModel
+			if err := json.Unmarshal(data, &inputs); err != nil {
+				t.Fatal(err)
+			}
+
+			canonical, err := CanonicalTableInputs(inputs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.MarshalIndent(canonical, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "canonical", format+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("canonical inputs differ from %s:\n%s", golden, got)
+			}
+
+			// the canonical form is stable
+			again, err := CanonicalTableInputs(canonical)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if againData, _ := json.MarshalIndent(again, "", "  "); string(againData)+"\n" != string(got) {
+				t.Errorf("canonical inputs aren't stable:\n%s", againData)
+			}
+
+			// the canonical form doesn't modify the inputs
+			if original, _ := json.Marshal(inputs); !JSONEqual(string(original), mustRemarshal(t, data)) {
+				t.Errorf("inputs were modified: %s", original)
+			}
+		})
+	}
+}
+
+// mustRemarshal decodes and encodes the inputs, so they can
+// be compared with the inputs that are encoded by the model.
+func mustRemarshal(t *testing.T, data []byte) string {
+	var inputs []TableInputModel
+	if err := json.Unmarshal(data, &inputs); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -3,29 +3,50 @@ package provider
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"terraform-provider-sneller/sneller/api"
 	"terraform-provider-sneller/sneller/datasource"
 	"terraform-provider-sneller/sneller/resource"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/providervalidator"
 	tpf_datasource "github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	tpf_resource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
-func New() provider.Provider {
-	return &snellerProvider{}
+// New returns a function that creates the provider. The version
+// is reported to Terraform and the Sneller API.
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &snellerProvider{version: version}
+	}
 }
 
-var _ provider.Provider = &snellerProvider{}
+var (
+	_ provider.Provider                     = &snellerProvider{}
+	_ provider.ProviderWithConfigValidators = &snellerProvider{}
+)
 
-type snellerProvider struct{}
+type snellerProvider struct {
+	version string
+}
 
 func (p *snellerProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "sneller"
+	resp.Version = p.version
 }
 
 func (p *snellerProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
@@ -33,52 +54,262 @@ func (p *snellerProvider) Schema(ctx context.Context, req provider.SchemaRequest
 		Description: "Terraform provider for interacting with Sneller.",
 		Attributes: map[string]schema.Attribute{
 			"token": schema.StringAttribute{
-				Description: "Sneller token to authenticate to the Sneller API. It defaults to the " + api.EnvSnellerToken + " " +
-					"environment variable.",
+				Description: "Sneller token to authenticate to the Sneller API. Only one of 'token', 'token_file' and " +
+					"'token_command' can be set. When none of them is set, then the token defaults to the " + api.EnvSnellerToken + " " +
+					"environment variable and then to the token of the profile.",
+				MarkdownDescription: "Sneller token to authenticate to the Sneller API. Only one of `token`, `token_file` and " +
+					"`token_command` can be set. When none of them is set, then the token defaults to the " + api.EnvSnellerToken + " " +
+					"environment variable and then to the token of the profile.",
 				Sensitive: true,
 				Optional:  true,
 			},
+			"token_file": schema.StringAttribute{
+				Description: "Path of a file that contains the Sneller token.",
+				Optional:    true,
+			},
+			"token_command": schema.ListAttribute{
+				Description: "Command (and arguments) that writes the Sneller token as a JSON object with the 'token' " +
+					"and an optional 'expiration' (RFC 3339) to its output. The command is run again when the token expires.",
+				MarkdownDescription: "Command (and arguments) that writes the Sneller token as a JSON object with the `token` " +
+					"and an optional `expiration` (RFC 3339) to its output. The command is run again when the token expires.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators:  []validator.List{listvalidator.SizeAtLeast(1)},
+			},
+			"profile": schema.StringAttribute{
+				Description: "Named profile in the Sneller configuration file (~/.sneller/config) that holds the token, " +
+					"region and endpoint. It defaults to the " + api.EnvSnellerProfile + " environment variable or the '" +
+					api.DefaultProfile + "' profile.",
+				MarkdownDescription: "Named profile in the Sneller configuration file (`~/.sneller/config`) that holds the token, " +
+					"region and endpoint. It defaults to the " + api.EnvSnellerProfile + " environment variable or the `" +
+					api.DefaultProfile + "` profile.",
+				Optional: true,
+			},
+			"tenant_id": schema.StringAttribute{
+				Description: "Tenant that is managed by the provider. It defaults to the " + api.EnvSnellerTenantID + " " +
+					"environment variable. If this variable isn't set, then the tenant that owns the token is managed. " +
+					"Use provider aliases to manage multiple tenants.",
+				Optional: true,
+			},
 			"default_region": schema.StringAttribute{
 				Description: "Default AWS region to use. It defaults to the " + api.EnvSnellerRegion + " " +
-					"environment variable. If this variable isn't set, then it default to us-east-1",
+					"environment variable. If this variable isn't set, then it defaults to the profile's region or us-east-1",
 				Optional: true,
 			},
 			"api_endpoint": schema.StringAttribute{
 				Description: "Endpoint of the Sneller API (intended for internal use).",
 				Optional:    true,
 			},
+			"query_endpoint": schema.StringAttribute{
+				Description: "Endpoint of the Sneller query API (intended for internal use). It defaults to the " +
+					api.EnvSnellerQueryEndpoint + " environment variable.",
+				Optional: true,
+			},
+			"endpoints": schema.MapAttribute{
+				Description: "Base URLs of the Sneller API per region (i.e. '{ us-east-1 = \"https://sneller.example.com:8443\" }'). " +
+					"They override the endpoint of the regions, which is derived from 'api_endpoint' otherwise.",
+				MarkdownDescription: "Base URLs of the Sneller API per region (i.e. `{ us-east-1 = \"https://sneller.example.com:8443\" }`). " +
+					"They override the endpoint of the regions, which is derived from `api_endpoint` otherwise.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"query_endpoints": schema.MapAttribute{
+				Description: "Base URLs of the Sneller query API per region. They override the query endpoint of the " +
+					"regions, which is derived from 'query_endpoint' otherwise.",
+				MarkdownDescription: "Base URLs of the Sneller query API per region. They override the query endpoint of the " +
+					"regions, which is derived from `query_endpoint` otherwise.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: fmt.Sprintf("Maximum number of times a failed Sneller API request is retried when "+
+					"the API is throttling or temporarily unavailable. It defaults to %d.", api.DefaultMaxRetries),
+				Optional:   true,
+				Validators: []validator.Int64{int64validator.AtLeast(0)},
+			},
+			"retry_max_wait": schema.StringAttribute{
+				Description:         fmt.Sprintf("Maximum time to wait between two retries (i.e. '30s'). It defaults to '%s'.", api.DefaultRetryMaxWait),
+				MarkdownDescription: fmt.Sprintf("Maximum time to wait between two retries (i.e. `30s`). It defaults to `%s`.", api.DefaultRetryMaxWait),
+				Optional:            true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Description: fmt.Sprintf("Maximum number of concurrent Sneller API requests per region. It defaults to %d. "+
+					"Set to 0 to disable the limit.", api.DefaultMaxConcurrentRequests),
+				Optional:   true,
+				Validators: []validator.Int64{int64validator.AtLeast(0)},
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Description: fmt.Sprintf("Maximum number of Sneller API requests per second per region. It defaults to %d. "+
+					"Set to 0 to disable the limit.", api.DefaultRequestsPerSecond),
+				Optional:   true,
+				Validators: []validator.Float64{float64validator.AtLeast(0)},
+			},
+			"skip_credentials_validation": schema.BoolAttribute{
+				Description: "Don't verify the access to the Sneller API. By default, the access is verified " +
+					"before the first request of a resource or data source.",
+				Optional: true,
+			},
+			"user_agent_suffix": schema.StringAttribute{
+				Description: "Suffix that is appended to the User-Agent header of the Sneller API requests " +
+					"(i.e. to identify the pipeline that runs Terraform).",
+				Optional: true,
+			},
+			"http_proxy": schema.StringAttribute{
+				Description: "URL of the proxy that is used to access the Sneller API (i.e. 'http://proxy.example.com:3128'). The scheme must be http, https or socks5. " +
+					"It defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.",
+				MarkdownDescription: "URL of the proxy that is used to access the Sneller API (i.e. `http://proxy.example.com:3128`). The scheme must be http, https or socks5. " +
+					"It defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.",
+				Optional:   true,
+				Validators: []validator.String{proxyURLValidator{}},
+			},
+			"ca_bundle_file": schema.StringAttribute{
+				Description: "Path of a file with PEM encoded CA certificates that are trusted in addition to the " +
+					"system's CA certificates. Only one of 'ca_bundle_file' and 'ca_bundle_pem' can be set.",
+				MarkdownDescription: "Path of a file with PEM encoded CA certificates that are trusted in addition to the " +
+					"system's CA certificates. Only one of `ca_bundle_file` and `ca_bundle_pem` can be set.",
+				Optional: true,
+			},
+			"ca_bundle_pem": schema.StringAttribute{
+				Description: "PEM encoded CA certificates that are trusted in addition to the system's CA certificates. " +
+					"Only one of 'ca_bundle_file' and 'ca_bundle_pem' can be set.",
+				MarkdownDescription: "PEM encoded CA certificates that are trusted in addition to the system's CA certificates. " +
+					"Only one of `ca_bundle_file` and `ca_bundle_pem` can be set.",
+				Optional: true,
+			},
+			"client_cert": schema.StringAttribute{
+				Description:         "PEM encoded client certificate for mutual TLS. It requires 'client_key' as well.",
+				MarkdownDescription: "PEM encoded client certificate for mutual TLS. It requires `client_key` as well.",
+				Optional:            true,
+			},
+			"client_key": schema.StringAttribute{
+				Description:         "PEM encoded private key of the client certificate for mutual TLS. It requires 'client_cert' as well.",
+				MarkdownDescription: "PEM encoded private key of the client certificate for mutual TLS. It requires `client_cert` as well.",
+				Sensitive:           true,
+				Optional:            true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Don't verify the certificate of the Sneller API. This is insecure and intended for " +
+					"local stand-ins of the Sneller API only.",
+				Optional: true,
+			},
+			"request_timeout": schema.StringAttribute{
+				Description: "Maximum time to wait for the response of a single Sneller API request (i.e. '1m'). " +
+					"Idempotent requests that time out are retried. Reading the response (i.e. " +
+					"streamed query results) isn't limited. By default, requests are only limited by the timeouts " +
+					"of the resources.",
+				MarkdownDescription: "Maximum time to wait for the response of a single Sneller API request (i.e. `1m`). " +
+					"Idempotent requests that time out are retried. Reading the response (i.e. " +
+					"streamed query results) isn't limited. By default, requests are only limited by the timeouts " +
+					"of the resources.",
+				Optional: true,
+			},
 		},
 	}
 }
 
 type snellerProviderModel struct {
-	Token         types.String `tfsdk:"token"`
-	DefaultRegion types.String `tfsdk:"default_region"`
-	Endpoint      types.String `tfsdk:"api_endpoint"`
+	Token                 types.String      `tfsdk:"token"`
+	TokenFile             types.String      `tfsdk:"token_file"`
+	TokenCommand          []string          `tfsdk:"token_command"`
+	Profile               types.String      `tfsdk:"profile"`
+	TenantID              types.String      `tfsdk:"tenant_id"`
+	DefaultRegion         types.String      `tfsdk:"default_region"`
+	Endpoint              types.String      `tfsdk:"api_endpoint"`
+	Endpoints             map[string]string `tfsdk:"endpoints"`
+	QueryEndpoint         types.String      `tfsdk:"query_endpoint"`
+	QueryEndpoints        map[string]string `tfsdk:"query_endpoints"`
+	MaxRetries            types.Int64       `tfsdk:"max_retries"`
+	RetryMaxWait          types.String      `tfsdk:"retry_max_wait"`
+	MaxConcurrentRequests types.Int64       `tfsdk:"max_concurrent_requests"`
+	RequestsPerSecond     types.Float64     `tfsdk:"requests_per_second"`
+	HTTPProxy             types.String      `tfsdk:"http_proxy"`
+	CABundleFile          types.String      `tfsdk:"ca_bundle_file"`
+	CABundlePEM           types.String      `tfsdk:"ca_bundle_pem"`
+	ClientCert            types.String      `tfsdk:"client_cert"`
+	ClientKey             types.String      `tfsdk:"client_key"`
+	InsecureSkipVerify    types.Bool        `tfsdk:"insecure_skip_verify"`
+	RequestTimeout        types.String      `tfsdk:"request_timeout"`
+	SkipCredentials       types.Bool        `tfsdk:"skip_credentials_validation"`
+	UserAgentSuffix       types.String      `tfsdk:"user_agent_suffix"`
 }
 
 func (p *snellerProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	if !req.Config.Raw.IsFullyKnown() {
+		// The configuration depends on values that are only known
+		// during apply (i.e. a token that is created by another
+		// resource), so the client is configured during apply.
+		// Resources keep their prior state until then.
+		tflog.Warn(ctx, "Provider configuration has unknown values, deferring configuration until apply")
+		return
+	}
+
 	var data snellerProviderModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 
-	// Check configuration data, which should take precedence over
-	token := os.Getenv(api.EnvSnellerToken)
-	if data.Token.ValueString() != "" {
-		token = data.Token.ValueString()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	profile, err := api.LoadDefaultProfile(data.Profile.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("profile"),
+			"Cannot load profile",
+			fmt.Sprintf("Unable to load the Sneller profile: %v", err.Error()),
+		)
+		return
+	}
+	if profile == nil {
+		profile = &api.Profile{}
+	}
+
+	// The token is obtained from (in order of precedence) the
+	// token, token_file or token_command attributes, the
+	// environment or the profile.
+	var tokenSource api.TokenSource
+	token := data.Token.ValueString()
+	switch {
+	case token != "":
+	case data.TokenFile.ValueString() != "":
+		token, err = api.ReadTokenFile(data.TokenFile.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("token_file"),
+				"Cannot read token file",
+				fmt.Sprintf("Unable to read the Sneller token: %v", err.Error()),
+			)
+			return
+		}
+	case len(data.TokenCommand) > 0:
+		tokenSource = api.NewCommandTokenSource(data.TokenCommand)
+	default:
+		token = os.Getenv(api.EnvSnellerToken)
+		if token == "" {
+			token = profile.Token
+		}
 	}
-	if token == "" {
+	if token == "" && tokenSource == nil {
 		resp.Diagnostics.AddError(
 			"Missing token",
 			"While configuring the provider, the token was not found in "+
-				"the "+api.EnvSnellerToken+" environment variable or provider "+
-				"configuration block token attribute.",
+				"the token, token_file or token_command attributes of the "+
+				"provider configuration block, the "+api.EnvSnellerToken+" "+
+				"environment variable or the Sneller profile.",
 		)
 	}
 
+	tenantID := os.Getenv(api.EnvSnellerTenantID)
+	if data.TenantID.ValueString() != "" {
+		tenantID = data.TenantID.ValueString()
+	}
+
 	defaultRegion := os.Getenv(api.EnvSnellerRegion)
 	if data.DefaultRegion.ValueString() != "" {
 		defaultRegion = data.DefaultRegion.ValueString()
 	}
+	if defaultRegion == "" {
+		defaultRegion = profile.Region
+	}
 	if defaultRegion == "" {
 		defaultRegion = api.DefaultSnellerRegion
 	}
@@ -87,35 +318,251 @@ func (p *snellerProvider) Configure(ctx context.Context, req provider.ConfigureR
 	if data.Endpoint.ValueString() != "" {
 		apiEndPoint = data.Endpoint.ValueString()
 	}
+	if apiEndPoint == "" {
+		apiEndPoint = profile.ApiEndpoint
+	}
 	if apiEndPoint == "" {
 		apiEndPoint = api.DefaultApiEndPoint
 	}
-	apiURL, err := url.Parse(apiEndPoint)
+	apiURL, err := api.ParseEndpoint(apiEndPoint)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Invalid API url",
-			fmt.Sprintf("The Sneller API url %q is invalid", apiEndPoint),
+			fmt.Sprintf("The Sneller API url %q is invalid: %v", apiEndPoint, err.Error()),
 		)
 		return
 	}
 
-	c := api.Client{
-		Token:         token,
-		DefaultRegion: defaultRegion,
-		ApiURL:        apiURL,
+	queryEndPoint := os.Getenv(api.EnvSnellerQueryEndpoint)
+	if data.QueryEndpoint.ValueString() != "" {
+		queryEndPoint = data.QueryEndpoint.ValueString()
+	}
+	var queryURL *url.URL
+	if queryEndPoint != "" {
+		queryURL, err = api.ParseEndpoint(queryEndPoint)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("query_endpoint"),
+				"Invalid query API url",
+				fmt.Sprintf("The Sneller query API url %q is invalid: %v", queryEndPoint, err.Error()),
+			)
+			return
+		}
+	}
+
+	endpoints := make(map[string]*url.URL, len(data.Endpoints))
+	for region, endpoint := range data.Endpoints {
+		endpoints[region], err = api.ParseEndpoint(endpoint)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("endpoints").AtMapKey(region),
+				"Invalid API url",
+				fmt.Sprintf("The Sneller API url %q of region %s is invalid: %v", endpoint, region, err.Error()),
+			)
+		}
+	}
+	queryEndpoints := make(map[string]*url.URL, len(data.QueryEndpoints))
+	for region, endpoint := range data.QueryEndpoints {
+		queryEndpoints[region], err = api.ParseEndpoint(endpoint)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("query_endpoints").AtMapKey(region),
+				"Invalid query API url",
+				fmt.Sprintf("The Sneller query API url %q of region %s is invalid: %v", endpoint, region, err.Error()),
+			)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	maxRetries := api.DefaultMaxRetries
+	if !data.MaxRetries.IsNull() {
+		maxRetries = int(data.MaxRetries.ValueInt64())
 	}
 
-	if err = c.Ping(ctx, defaultRegion); err != nil {
+	retryMaxWait := api.DefaultRetryMaxWait
+	if data.RetryMaxWait.ValueString() != "" {
+		retryMaxWait, err = time.ParseDuration(data.RetryMaxWait.ValueString())
+		if err != nil || retryMaxWait <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("retry_max_wait"),
+				"Invalid retry wait time",
+				fmt.Sprintf("The maximum retry wait time %q is not a valid positive duration (i.e. '30s')", data.RetryMaxWait.ValueString()),
+			)
+			return
+		}
+	}
+
+	maxConcurrent := api.DefaultMaxConcurrentRequests
+	if !data.MaxConcurrentRequests.IsNull() {
+		maxConcurrent = int(data.MaxConcurrentRequests.ValueInt64())
+	}
+	requestsPerSecond := float64(api.DefaultRequestsPerSecond)
+	if !data.RequestsPerSecond.IsNull() {
+		requestsPerSecond = data.RequestsPerSecond.ValueFloat64()
+	}
+
+	transportConfig := api.TransportConfig{
+		CABundle:           []byte(data.CABundlePEM.ValueString()),
+		ClientCert:         []byte(data.ClientCert.ValueString()),
+		ClientKey:          []byte(data.ClientKey.ValueString()),
+		InsecureSkipVerify: data.InsecureSkipVerify.ValueBool(),
+	}
+	if data.HTTPProxy.ValueString() != "" {
+		transportConfig.ProxyURL, err = url.Parse(data.HTTPProxy.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("http_proxy"),
+				"Invalid proxy url",
+				fmt.Sprintf("The proxy url %q is invalid: %v", data.HTTPProxy.ValueString(), err.Error()),
+			)
+			return
+		}
+	}
+	if data.CABundleFile.ValueString() != "" {
+		transportConfig.CABundle, err = os.ReadFile(data.CABundleFile.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ca_bundle_file"),
+				"Cannot read CA bundle",
+				fmt.Sprintf("Unable to read the CA bundle: %v", err.Error()),
+			)
+			return
+		}
+	}
+	transport, err := api.NewTransport(transportConfig)
+	if err != nil {
 		resp.Diagnostics.AddError(
-			"Cannot access Sneller API",
-			fmt.Sprintf("The Sneller API cannot be contacted: %v", err.Error()),
+			"Invalid TLS configuration",
+			fmt.Sprintf("Unable to configure the connection to the Sneller API: %v", err.Error()),
 		)
+		return
+	}
+
+	var requestTimeout time.Duration
+	if data.RequestTimeout.ValueString() != "" {
+		requestTimeout, err = time.ParseDuration(data.RequestTimeout.ValueString())
+		if err != nil || requestTimeout <= 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("request_timeout"),
+				"Invalid request timeout",
+				fmt.Sprintf("The request timeout %q is not a valid positive duration (i.e. '1m')", data.RequestTimeout.ValueString()),
+			)
+			return
+		}
 	}
 
+	// the default region and all regions with an endpoint
+	// override are verified when the API is first used
+	var verifier *api.Verifier
+	if !data.SkipCredentials.ValueBool() {
+		regions := append([]string{defaultRegion}, maps.Keys(endpoints)...)
+		slices.Sort(regions)
+		verifier = api.NewVerifier(slices.Compact(regions)...)
+	}
+
+	c := api.Client{
+		Client: &http.Client{
+			Transport: api.NewLoggingTransport(transport),
+		},
+		TenantID:       tenantID,
+		Token:          token,
+		TokenSource:    tokenSource,
+		DefaultRegion:  defaultRegion,
+		ApiURL:         apiURL,
+		Endpoints:      endpoints,
+		QueryURL:       queryURL,
+		QueryEndpoints: queryEndpoints,
+		MaxRetries:     maxRetries,
+		RetryMaxWait:   retryMaxWait,
+		RequestTimeout: requestTimeout,
+		TenantCache:    api.NewTenantCache(api.DefaultTenantCacheTTL),
+		Limiter:        api.NewLimiter(maxConcurrent, requestsPerSecond),
+		Verifier:       verifier,
+		UserAgent:      api.UserAgent(p.version, req.TerraformVersion, data.UserAgentSuffix.ValueString()),
+	}
+
+	tflog.Debug(ctx, "Configured Sneller API client", map[string]any{
+		"api_endpoint":                apiEndPoint,
+		"endpoints":                   data.Endpoints,
+		"query_endpoints":             data.QueryEndpoints,
+		"tenant_id":                   tenantID,
+		"profile":                     profile.Name,
+		"default_region":              defaultRegion,
+		"max_retries":                 maxRetries,
+		"retry_max_wait":              retryMaxWait.String(),
+		"max_concurrent_requests":     maxConcurrent,
+		"requests_per_second":         requestsPerSecond,
+		"http_proxy":                  redactedURL(transportConfig.ProxyURL),
+		"insecure_skip_verify":        transportConfig.InsecureSkipVerify,
+		"request_timeout":             requestTimeout.String(),
+		"skip_credentials_validation": verifier == nil,
+		"user_agent":                  c.UserAgent,
+	})
+
 	resp.DataSourceData = &c
 	resp.ResourceData = &c
 }
 
+// redactedURL returns the URL without its password, so it can
+// be logged.
+func redactedURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// proxySchemes are the proxy URL schemes that are supported by
+// the HTTP transport.
+var proxySchemes = []string{"http", "https", "socks5"}
+
+// proxyURLValidator validates that a proxy URL is an absolute URL
+// with a supported scheme.
+type proxyURLValidator struct{}
+
+func (v proxyURLValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("value must be an absolute URL with one of the schemes %q", proxySchemes)
+}
+
+func (v proxyURLValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v proxyURLValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	value := req.ConfigValue.ValueString()
+	u, err := url.Parse(value)
+	if err != nil || !slices.Contains(proxySchemes, u.Scheme) || u.Host == "" {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid proxy url",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), value),
+		)
+	}
+}
+
+func (p *snellerProvider) ConfigValidators(ctx context.Context) []provider.ConfigValidator {
+	return []provider.ConfigValidator{
+		providervalidator.Conflicting(
+			path.MatchRoot("token"),
+			path.MatchRoot("token_file"),
+			path.MatchRoot("token_command"),
+		),
+		providervalidator.Conflicting(
+			path.MatchRoot("ca_bundle_file"),
+			path.MatchRoot("ca_bundle_pem"),
+		),
+		providervalidator.RequiredTogether(
+			path.MatchRoot("client_cert"),
+			path.MatchRoot("client_key"),
+		),
+	}
+}
+
 func (p *snellerProvider) Resources(context.Context) []func() tpf_resource.Resource {
 	return []func() tpf_resource.Resource{
 		resource.NewElasticProxyResource,
@@ -130,6 +577,7 @@ func (p *snellerProvider) DataSources(context.Context) []func() tpf_datasource.D
 		datasource.NewDatabasesDataSource,
 		datasource.NewDatabaseDataSource,
 		datasource.NewElasticProxyDataSource,
+		datasource.NewQueryDataSource,
 		datasource.NewTableDataSource,
 		datasource.NewTenantDataSource,
 		datasource.NewTenantRegionDataSource,
@@ -2,17 +2,19 @@ package resource
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 func NewElasticProxyResource() resource.Resource {
@@ -32,11 +34,13 @@ type elasticProxyResource struct {
 
 type elasticProxyResourceModel struct {
 	ID       types.String                              `tfsdk:"id"`
+	TenantID types.String                              `tfsdk:"tenant_id"`
 	Region   types.String                              `tfsdk:"region"`
 	Location types.String                              `tfsdk:"location"`
 	LogPath  types.String                              `tfsdk:"log_path"`
 	LogFlags *elasticProxyLogFlagsResourceModel        `tfsdk:"log_flags"`
 	Index    map[string]elasticProxyIndexResourceModel `tfsdk:"index"`
+	Timeouts timeouts.Value                            `tfsdk:"timeouts"`
 }
 
 type elasticProxyLogFlagsResourceModel struct {
@@ -74,6 +78,7 @@ func (r *elasticProxyResource) Schema(ctx context.Context, req resource.SchemaRe
 				Computed:      true,
 				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
+			"tenant_id": tenantIDAttribute(),
 			"region": schema.StringAttribute{
 				Description: "Region for which to configure the Elastic Proxy. If not set, then the configuration is assumed to be located in the tenant's home region.",
 				Optional:    true,
@@ -181,6 +186,9 @@ func (r *elasticProxyResource) Schema(ctx context.Context, req resource.SchemaRe
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
 	}
 }
 
@@ -192,6 +200,11 @@ func (r *elasticProxyResource) Configure(ctx context.Context, req resource.Confi
 }
 
 func (r *elasticProxyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		// the prior state is kept until the provider is
+		// configured (see providerdata.CheckClient)
+		return
+	}
 
 	var data elasticProxyResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -199,18 +212,22 @@ func (r *elasticProxyResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "read", data.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	region := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -218,26 +235,18 @@ func (r *elasticProxyResource) Read(ctx context.Context, req resource.ReadReques
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
-		return
-	}
-
-	tenantInfo, err = r.client.Tenant(ctx, region)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Cannot get tenant info",
-			fmt.Sprintf("Unable to get tenant info in region %s: %v", region, err.Error()),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
 	elasticProxyPath := fmt.Sprintf("%s/%selastic-proxy.json", tenantInfo.Regions[region].Bucket, api.DefaultDbPrefix)
-	config, err := r.client.ElasticProxyConfig(ctx, region)
+	config, etag, err := client.ElasticProxyConfig(ctx, region)
 	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			tflog.Debug(ctx, "Elastic proxy configuration not found, removing from state", map[string]any{"region": region})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Cannot get elastic-proxy configuration",
 			fmt.Sprintf("Unable to get elastic-proxy configuration in region %s: %v", region, err.Error()),
@@ -245,7 +254,10 @@ func (r *elasticProxyResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
+	setETag(ctx, resp.Private, etag, &resp.Diagnostics)
+
 	data.ID = types.StringValue(fmt.Sprintf("%s/%s", tenantInfo.TenantID, region))
+	data.TenantID = types.StringValue(tenantInfo.TenantID)
 	data.Region = types.StringValue(region)
 	data.Location = types.StringValue(elasticProxyPath)
 	data.LogPath = types.StringValue(config.LogPath)
@@ -294,6 +306,10 @@ func (r *elasticProxyResource) Read(ctx context.Context, req resource.ReadReques
 }
 
 func (r *elasticProxyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data elasticProxyResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -301,8 +317,16 @@ func (r *elasticProxyResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, "create", data.Timeouts.Create, defaultCreateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
 	region := data.Region.ValueString()
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -317,7 +341,7 @@ func (r *elasticProxyResource) Create(ctx context.Context, req resource.CreateRe
 
 	config := elasticProxyConfigFromData(data)
 
-	err = r.client.SetElasticProxyConfig(ctx, region, config)
+	etag, err := client.SetElasticProxyConfig(ctx, region, config, "")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot create elastic proxy configuration",
@@ -325,8 +349,10 @@ func (r *elasticProxyResource) Create(ctx context.Context, req resource.CreateRe
 		)
 		return
 	}
+	setETag(ctx, resp.Private, etag, &resp.Diagnostics)
 
 	data.ID = types.StringValue(fmt.Sprintf("%s/%s", tenantInfo.TenantID, region))
+	data.TenantID = types.StringValue(tenantInfo.TenantID)
 	data.Region = types.StringValue(region)
 	data.Location = types.StringValue(fmt.Sprintf("%s/%selastic-proxy.json", tenantInfo.Regions[region].Bucket, api.DefaultDbPrefix))
 
@@ -334,6 +360,10 @@ func (r *elasticProxyResource) Create(ctx context.Context, req resource.CreateRe
 }
 
 func (r *elasticProxyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data elasticProxyResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -341,18 +371,22 @@ func (r *elasticProxyResource) Update(ctx context.Context, req resource.UpdateRe
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "update", data.Timeouts.Update, defaultUpdateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	region := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -360,24 +394,23 @@ func (r *elasticProxyResource) Update(ctx context.Context, req resource.UpdateRe
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
 	config := elasticProxyConfigFromData(data)
 
-	err = r.client.SetElasticProxyConfig(ctx, region, config)
+	etag, err := client.SetElasticProxyConfig(ctx, region, config, getETag(ctx, req.Private, &resp.Diagnostics))
 	if err != nil {
-		resp.Diagnostics.AddError(
+		addWriteError(
+			&resp.Diagnostics,
 			"Cannot update elastic proxy configuration",
-			fmt.Sprintf("Unable to update elastic proxy configuration in region %s: %v", region, err.Error()),
+			fmt.Sprintf("Unable to update elastic proxy configuration in region %s", region),
+			err,
 		)
 		return
 	}
+	setETag(ctx, resp.Private, etag, &resp.Diagnostics)
 
 	data.Location = types.StringValue(fmt.Sprintf("%s/%selastic-proxy.json", tenantInfo.Regions[region].Bucket, api.DefaultDbPrefix))
 
@@ -385,6 +418,10 @@ func (r *elasticProxyResource) Update(ctx context.Context, req resource.UpdateRe
 }
 
 func (r *elasticProxyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data elasticProxyResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -392,18 +429,22 @@ func (r *elasticProxyResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "delete", data.Timeouts.Delete, defaultDeleteTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	region := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -411,15 +452,11 @@ func (r *elasticProxyResource) Delete(ctx context.Context, req resource.DeleteRe
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
-	err = r.client.DeleteElasticProxyConfig(ctx, region)
+	err = client.DeleteElasticProxyConfig(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot delete elastic proxy configuration",
@@ -430,8 +467,7 @@ func (r *elasticProxyResource) Delete(ctx context.Context, req resource.DeleteRe
 }
 
 func (r *elasticProxyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importState(ctx, r.client, 2, resolveRegionID, req, resp)
 }
 
 func elasticProxyConfigFromData(data elasticProxyResourceModel) api.ElasticProxyConfig {
@@ -1,5 +1,136 @@
 package resource
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"terraform-provider-sneller/sneller/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
 func ptr[T any](t T) *T {
 	return &t
 }
+
+// parseID splits the Terraform identifier in the expected
+// number of parts. It adds an error diagnostic and returns
+// nil when the identifier is invalid.
+func parseID(ctx context.Context, id string, n int, diags *diag.Diagnostics) []string {
+	parts := strings.Split(id, "/")
+	if len(parts) != n {
+		tflog.Debug(ctx, "Cannot parse resource ID", map[string]any{
+			"id":             id,
+			"parts":          len(parts),
+			"expected_parts": n,
+		})
+		diags.AddError(
+			"Cannot parse ID",
+			fmt.Sprintf("Invalid ID %q", id),
+		)
+		return nil
+	}
+	tflog.Debug(ctx, "Parsed resource ID", map[string]any{
+		"id":    id,
+		"parts": parts,
+	})
+	return parts
+}
+
+// verifyTenant checks whether the resource belongs to the tenant
+// that the provider is authenticated for. It adds an error
+// diagnostic and returns false when it doesn't.
+func verifyTenant(ctx context.Context, tenantInfo *api.TenantInfo, tenantID string, diags *diag.Diagnostics) bool {
+	if tenantInfo.TenantID != tenantID {
+		tflog.Debug(ctx, "Tenant mismatch", map[string]any{
+			"expected_tenant_id": tenantID,
+			"actual_tenant_id":   tenantInfo.TenantID,
+		})
+		diags.AddError(
+			"Invalid tenant",
+			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
+		)
+		return false
+	}
+	tflog.Debug(ctx, "Verified tenant", map[string]any{
+		"tenant_id": tenantID,
+	})
+	return true
+}
+
+// tenantIDAttribute returns the `tenant_id` attribute that
+// can be used to override the tenant of the provider.
+func tenantIDAttribute() schema.StringAttribute {
+	return schema.StringAttribute{
+		Description: "Tenant that owns the resource. If not set, then the provider's tenant is used.",
+		Optional:    true,
+		Computed:    true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+}
+
+// importTenantID sets the tenant_id attribute to the tenant that
+// is encoded in the imported identifier, so resources of other
+// tenants than the provider's tenant can be imported as well.
+func importTenantID(ctx context.Context, id string, resp *resource.ImportStateResponse) {
+	if tenantID, _, ok := strings.Cut(id, "/"); ok && tenantID != "" {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("tenant_id"), tenantID)...)
+	}
+}
+
+// etagKey is the key of the entity tag in the private state.
+const etagKey = "etag"
+
+// privateState is implemented by the private state data of the
+// resource requests and responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// getETag returns the entity tag of the object when it was last
+// read or written. It returns an empty string when it is unknown,
+// so the object is written unconditionally.
+func getETag(ctx context.Context, private privateState, diags *diag.Diagnostics) string {
+	value, getDiags := private.GetKey(ctx, etagKey)
+	diags.Append(getDiags...)
+	var etag string
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &etag); err != nil {
+			tflog.Debug(ctx, "Ignoring invalid entity tag", map[string]any{"etag": string(value)})
+		}
+	}
+	return etag
+}
+
+// setETag stores the entity tag of the object, so subsequent
+// writes fail when it was modified outside of Terraform.
+func setETag(ctx context.Context, private privateState, etag string, diags *diag.Diagnostics) {
+	value, _ := json.Marshal(etag)
+	diags.Append(private.SetKey(ctx, etagKey, value)...)
+}
+
+// addWriteError adds an error diagnostic for a failed write. When
+// the object was modified since it was last read, then it asks to
+// re-run the plan instead of overwriting the modifications.
+func addWriteError(diags *diag.Diagnostics, summary, detail string, err error) {
+	if errors.Is(err, api.ErrPreconditionFailed) {
+		diags.AddError(
+			"Resource modified outside of Terraform",
+			fmt.Sprintf("%s: it was modified outside of Terraform since it was last read. Re-run `terraform plan` to review the changes before applying them.", detail),
+		)
+		return
+	}
+	diags.AddError(summary, fmt.Sprintf("%s: %v", detail, err.Error()))
+}
@@ -0,0 +1,138 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/exp/slices"
+)
+
+// importResolver returns the full identifier of a resource from
+// the parts of a short import identifier, which doesn't include
+// the tenant (and possibly other parts).
+type importResolver func(ctx context.Context, client *api.Client, tenantInfo *api.TenantInfo, parts []string) (string, error)
+
+// importState imports a resource. The full identifier (which
+// starts with the tenant and has the given number of parts) is
+// imported as-is. Shorter identifiers belong to the provider's
+// tenant and the missing parts are resolved using the Sneller API.
+func importState(ctx context.Context, client *api.Client, n int, resolve importResolver, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) > n || slices.Contains(parts, "") {
+		resp.Diagnostics.AddError(
+			"Cannot parse ID",
+			fmt.Sprintf("Invalid ID %q", req.ID),
+		)
+		return
+	}
+	if len(parts) == n {
+		// Retrieve import ID and save to id attribute
+		resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+		importTenantID(ctx, req.ID, resp)
+		return
+	}
+
+	if !providerdata.CheckClient(client, &resp.Diagnostics) {
+		return
+	}
+	tenantInfo, err := client.Tenant(ctx, "")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Cannot get tenant info",
+			fmt.Sprintf("Unable to get tenant info: %v", err.Error()),
+		)
+		return
+	}
+
+	id, err := resolve(ctx, client, tenantInfo, parts)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Cannot resolve ID",
+			fmt.Sprintf("Unable to resolve ID %q: %v", req.ID, err.Error()),
+		)
+		return
+	}
+	tflog.Debug(ctx, "Resolved import ID", map[string]any{"import_id": req.ID, "id": id})
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("tenant_id"), tenantInfo.TenantID)...)
+}
+
+// resolveRegionID resolves the `<region>` import identifier.
+func resolveRegionID(ctx context.Context, client *api.Client, tenantInfo *api.TenantInfo, parts []string) (string, error) {
+	return fmt.Sprintf("%s/%s", tenantInfo.TenantID, parts[0]), nil
+}
+
+// resolveTableID resolves the `<region>/<database>/<table>` and
+// `<database>/<table>` import identifiers. Without a region, the
+// table is looked up in all regions of the tenant.
+func resolveTableID(ctx context.Context, client *api.Client, tenantInfo *api.TenantInfo, parts []string) (string, error) {
+	if len(parts) == 3 {
+		return fmt.Sprintf("%s/%s", tenantInfo.TenantID, strings.Join(parts, "/")), nil
+	}
+	if len(parts) != 2 {
+		return "", errors.New("expected <database>/<table> or <region>/<database>/<table>")
+	}
+
+	database, table := parts[0], parts[1]
+	var regions []string
+	for region, regionInfo := range tenantInfo.Regions {
+		if regionInfo.Bucket == "" {
+			// regions without a bucket don't have tables
+			continue
+		}
+		_, err := client.TableInfo(ctx, region, database, table)
+		if errors.Is(err, api.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("unable to get table info in region %s: %w", region, err)
+		}
+		regions = append(regions, region)
+	}
+
+	switch len(regions) {
+	case 0:
+		return "", fmt.Errorf("table %s/%s doesn't exist in any region", database, table)
+	case 1:
+		return fmt.Sprintf("%s/%s/%s/%s", tenantInfo.TenantID, regions[0], database, table), nil
+	}
+	sort.Strings(regions)
+	return "", fmt.Errorf("table %s/%s exists in multiple regions (%s), use <region>/<database>/<table> instead", database, table, strings.Join(regions, ", "))
+}
+
+// resolveUserID resolves the `<user-id>` and `<email>` import
+// identifiers.
+func resolveUserID(ctx context.Context, client *api.Client, tenantInfo *api.TenantInfo, parts []string) (string, error) {
+	if !strings.Contains(parts[0], "@") {
+		return fmt.Sprintf("%s/%s", tenantInfo.TenantID, parts[0]), nil
+	}
+
+	users, err := client.Users(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to get users: %w", err)
+	}
+	var userIDs []string
+	for _, user := range users {
+		if strings.EqualFold(user.Email, parts[0]) {
+			userIDs = append(userIDs, user.UserID)
+		}
+	}
+
+	switch len(userIDs) {
+	case 0:
+		return "", fmt.Errorf("no user with email address %s", parts[0])
+	case 1:
+		return fmt.Sprintf("%s/%s", tenantInfo.TenantID, userIDs[0]), nil
+	}
+	sort.Strings(userIDs)
+	return "", fmt.Errorf("multiple users with email address %s (%s), use the user identifier instead", parts[0], strings.Join(userIDs, ", "))
+}
@@ -0,0 +1,168 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/snellertest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func testImportClient(t *testing.T) (*snellertest.Server, *api.Client) {
+	srv := snellertest.NewServer()
+	t.Cleanup(srv.Close)
+
+	apiURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &api.Client{
+		Token:         srv.Token,
+		DefaultRegion: api.DefaultSnellerRegion,
+		ApiURL:        apiURL,
+	}
+	return srv, client
+}
+
+func TestResolveTableID(t *testing.T) {
+	ctx := context.Background()
+	srv, client := testImportClient(t)
+
+	for _, region := range []string{"us-east-1", "us-west-2"} {
+		if err := client.SetBucket(ctx, region, "s3://cache-"+region, "arn:aws:iam::111111111111:role/test"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.SetTable(ctx, region, "db", "shared", []byte(`{"input":[]}`), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.SetTable(ctx, "us-west-2", "db", "table", []byte(`{"input":[]}`), ""); err != nil {
+		t.Fatal(err)
+	}
+	tenantInfo, err := client.Tenant(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id, expected, err string
+	}{
+		{id: "us-east-1/db/shared", expected: srv.TenantID + "/us-east-1/db/shared"},
+		{id: "db/table", expected: srv.TenantID + "/us-west-2/db/table"},
+		{id: "db/shared", err: "multiple regions (us-east-1, us-west-2)"},
+		{id: "db/missing", err: "doesn't exist in any region"},
+		{id: "db", err: "expected <database>/<table>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			id, err := resolveTableID(ctx, client, tenantInfo, strings.Split(tt.id, "/"))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tt.expected {
+				t.Errorf("expected ID %q, got %q", tt.expected, id)
+			}
+		})
+	}
+}
+
+func TestResolveUserID(t *testing.T) {
+	ctx := context.Background()
+	srv, client := testImportClient(t)
+
+	userID, err := client.CreateUser(ctx, "user@example.com", false, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// email addresses are compared case-insensitive
+	for _, email := range []string{"shared@example.com", "Shared@Example.com"} {
+		if _, err := client.CreateUser(ctx, email, false, nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tenantInfo, err := client.Tenant(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id, expected, err string
+	}{
+		{id: "User@Example.com", expected: srv.TenantID + "/" + userID},
+		{id: userID, expected: srv.TenantID + "/" + userID},
+		{id: "shared@example.com", err: "multiple users"},
+		{id: "missing@example.com", err: "no user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			id, err := resolveUserID(ctx, client, tenantInfo, []string{tt.id})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tt.expected {
+				t.Errorf("expected ID %q, got %q", tt.expected, id)
+			}
+		})
+	}
+}
+
+func TestImportState(t *testing.T) {
+	ctx := context.Background()
+	srv, client := testImportClient(t)
+
+	var schemaResp resource.SchemaResponse
+	(&tenantRegionResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	tests := []struct {
+		id, expected, tenantID string
+		ok                     bool
+	}{
+		{id: "TOTHERTENANT/us-west-2", expected: "TOTHERTENANT/us-west-2", tenantID: "TOTHERTENANT", ok: true},
+		{id: "us-west-2", expected: srv.TenantID + "/us-west-2", tenantID: srv.TenantID, ok: true},
+		{id: "/us-west-2"},
+		{id: "tenant/us-west-2/extra"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			resp := resource.ImportStateResponse{
+				State: tfsdk.State{
+					Schema: schemaResp.Schema,
+					Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+				},
+			}
+			importState(ctx, client, 2, resolveRegionID, resource.ImportStateRequest{ID: tt.id}, &resp)
+			if ok := !resp.Diagnostics.HasError(); ok != tt.ok {
+				t.Fatalf("expected success %v, got %v", tt.ok, resp.Diagnostics)
+			}
+			if !tt.ok {
+				return
+			}
+
+			var id, tenantID types.String
+			resp.State.GetAttribute(ctx, path.Root("id"), &id)
+			resp.State.GetAttribute(ctx, path.Root("tenant_id"), &tenantID)
+			if id.ValueString() != tt.expected || tenantID.ValueString() != tt.tenantID {
+				t.Errorf("unexpected ID %s (tenant %s)", id, tenantID)
+			}
+		})
+	}
+}
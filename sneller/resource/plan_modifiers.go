@@ -106,3 +106,38 @@ func (apm *int64DefaultValuePlanModifier) PlanModifyInt64(ctx context.Context, r
 
 	res.PlanValue = apm.DefaultValue
 }
+
+// StringSiblingValue plans the configured value of the sibling
+// attribute when the attribute isn't configured. It is used for
+// attributes that are (deprecated) aliases of each other.
+func StringSiblingValue(name string) planmodifier.String {
+	return &stringSiblingValuePlanModifier{name}
+}
+
+type stringSiblingValuePlanModifier struct {
+	Name string
+}
+
+var _ planmodifier.String = (*stringSiblingValuePlanModifier)(nil)
+
+func (apm *stringSiblingValuePlanModifier) Description(ctx context.Context) string {
+	return fmt.Sprintf("defaults to the value of '%s'", apm.Name)
+}
+
+func (apm *stringSiblingValuePlanModifier) MarkdownDescription(ctx context.Context) string {
+	return fmt.Sprintf("defaults to the value of `%s`", apm.Name)
+}
+
+func (apm *stringSiblingValuePlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, res *planmodifier.StringResponse) {
+	// If the attribute configuration is not null, we are done here
+	if !req.ConfigValue.IsNull() {
+		return
+	}
+
+	var siblingValue types.String
+	res.Diagnostics.Append(req.Config.GetAttribute(ctx, req.Path.ParentPath().AtName(apm.Name), &siblingValue)...)
+	if res.Diagnostics.HasError() {
+		return
+	}
+	res.PlanValue = siblingValue
+}
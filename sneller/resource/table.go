@@ -1,25 +1,34 @@
 package resource
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 	"terraform-provider-sneller/sneller/model"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"golang.org/x/exp/slices"
 )
 
@@ -29,26 +38,42 @@ func NewTableResource() resource.Resource {
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &tableResource{}
-	_ resource.ResourceWithConfigure   = &tableResource{}
-	_ resource.ResourceWithImportState = &tableResource{}
+	_ resource.Resource                     = &tableResource{}
+	_ resource.ResourceWithConfigure        = &tableResource{}
+	_ resource.ResourceWithImportState      = &tableResource{}
+	_ resource.ResourceWithConfigValidators = &tableResource{}
+	_ resource.ResourceWithModifyPlan       = &tableResource{}
+	_ resource.ResourceWithUpgradeState     = &tableResource{}
 )
 
+// tableDefinitionAttributes are the attributes that hold the
+// (structured) table definition. They can't be combined with
+// the definition_json attribute.
+var tableDefinitionAttributes = []string{"inputs", "partitions", "retention_policy", "beta_features", "skip_backfill"}
+
 type tableResource struct {
 	client *api.Client
 }
 
 type tableResourceModel struct {
-	ID              types.String                `tfsdk:"id" json:"ignore"`
-	Region          types.String                `tfsdk:"region" json:"ignore"`
-	Database        types.String                `tfsdk:"database" json:"ignore"`
-	Location        types.String                `tfsdk:"location" json:"ignore"`
-	Table           *string                     `tfsdk:"table" json:"ignore"`
+	ID              types.String                `tfsdk:"id" json:"-"`
+	TenantID        types.String                `tfsdk:"tenant_id" json:"-"`
+	Region          types.String                `tfsdk:"region" json:"-"`
+	Database        types.String                `tfsdk:"database" json:"-"`
+	Location        types.String                `tfsdk:"location" json:"-"`
+	Table           *string                     `tfsdk:"table" json:"-"`
 	Inputs          []model.TableInputModel     `tfsdk:"inputs" json:"input"`
+	Input           []model.TableInputModel     `tfsdk:"input" json:"-"`
 	Partitions      []model.TablePartitionModel `tfsdk:"partitions" json:"partitions,omitempty"`
 	RetentionPolicy *model.TableRetentionModel  `tfsdk:"retention_policy" json:"retention_policy,omitempty"`
 	BetaFeatures    []string                    `tfsdk:"beta_features" json:"beta_features,omitempty"`
 	SkipBackfill    *bool                       `tfsdk:"skip_backfill" json:"skip_backfill,omitempty"`
+	DefinitionJSON  model.JSONValue             `tfsdk:"definition_json" json:"-"`
+	ExtraJSON       types.String                `tfsdk:"extra_json" json:"-"`
+	WaitForIndex    types.Bool                  `tfsdk:"wait_for_index" json:"-"`
+	HasDefinition   types.Bool                  `tfsdk:"has_definition" json:"-"`
+	HasIndex        types.Bool                  `tfsdk:"has_index" json:"-"`
+	Timeouts        timeouts.Value              `tfsdk:"timeouts" json:"-"`
 }
 
 func (r *tableResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -118,14 +143,89 @@ func (r *tableResource) Schema(ctx context.Context, req resource.SchemaRequest,
 		},
 	}
 
+	inputAttributes := map[string]schema.Attribute{
+		"pattern": schema.StringAttribute{
+			Description:         "Pattern definition to specify the source pattern (i.e. 's3://sneller-source-bucket/data/*.ndjson').",
+			MarkdownDescription: "Pattern definition to specify the source pattern (i.e. `s3://sneller-source-bucket/data/*.ndjson`).",
+			Required:            true,
+		},
+		"format": schema.StringAttribute{
+			Description:         fmt.Sprintf("Format of the input data ('%s').", strings.Join(api.Formats, "', '")),
+			MarkdownDescription: fmt.Sprintf("Format of the input data (`%s`).", strings.Join(api.Formats, "`, `")),
+			CustomType:          model.FormatType{},
+			Required:            true,
+			Validators:          []validator.String{stringvalidator.OneOf(api.Formats...)},
+		},
+		"json_hints": schema.ListNestedAttribute{
+			Description: "Ingestion hints for JSON input.",
+			Optional:    true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"path": schema.StringAttribute{
+						Description: "Path of the field (use dots to specify a subfield).",
+						Optional:    true,
+						Computed:    true,
+						Validators: []validator.String{
+							stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("field")),
+						},
+						PlanModifiers: []planmodifier.String{StringSiblingValue("field")},
+					},
+					"field": schema.StringAttribute{
+						Description:         "Field name. It is deprecated, use 'path' instead.",
+						MarkdownDescription: "Field name. It is deprecated, use `path` instead.",
+						DeprecationMessage:  "Use path instead.",
+						Optional:            true,
+						Computed:            true,
+						PlanModifiers:       []planmodifier.String{StringSiblingValue("path")},
+					},
+					"hints": schema.ListAttribute{
+						Description: "Hints.",
+						CustomType:  model.NewHintsType(),
+						Required:    true,
+						ElementType: types.StringType,
+					},
+				},
+			},
+			Validators: []validator.List{tableSupportedFormatsValidator{filterFormats("json")}},
+		},
+		"csv_hints": schema.SingleNestedAttribute{
+			Description: "Ingestion hints for CSV input.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"separator": schema.StringAttribute{
+					Description:         "specify a custom separator (defaults to ',').",
+					MarkdownDescription: "specify a custom separator (defaults to `,`).",
+					Optional:            true,
+					Validators:          []validator.String{stringvalidator.LengthBetween(1, 1)},
+				},
+				"skip_records":   skipRecords,
+				"missing_values": missingValues,
+				"fields":         fields,
+			},
+			Validators: []validator.Object{tableSupportedFormatsValidator{filterFormats("csv")}},
+		},
+		"tsv_hints": schema.SingleNestedAttribute{
+			Description: "Ingestion hints for TSV input.",
+			Optional:    true,
+			Attributes: map[string]schema.Attribute{
+				"skip_records":   skipRecords,
+				"missing_values": missingValues,
+				"fields":         fields,
+			},
+			Validators: []validator.Object{tableSupportedFormatsValidator{filterFormats("tsv")}},
+		},
+	}
+
 	resp.Schema = schema.Schema{
 		Description: "Configure a Sneller table.",
+		Version:     schemaVersion(tableSchemaUpgrades()),
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Description:   "Terraform identifier.",
 				Computed:      true,
 				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
+			"tenant_id": tenantIDAttribute(),
 			"region": schema.StringAttribute{
 				Description: "Region where the table should be created. If not set, then the table is created in the tenant's home region.",
 				Optional:    true,
@@ -152,70 +252,16 @@ func (r *tableResource) Schema(ctx context.Context, req resource.SchemaRequest,
 			},
 			"inputs": schema.ListNestedAttribute{
 				Description: "The input definition specifies where the source data is located and it format.",
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				NestedObject: schema.NestedAttributeObject{
-					Attributes: map[string]schema.Attribute{
-						"pattern": schema.StringAttribute{
-							Description:         "Pattern definition to specify the source pattern (i.e. 's3://sneller-source-bucket/data/*.ndjson').",
-							MarkdownDescription: "Pattern definition to specify the source pattern (i.e. `s3://sneller-source-bucket/data/*.ndjson`).",
-							Required:            true,
-						},
-						"format": schema.StringAttribute{
-							Description:         fmt.Sprintf("Format of the input data ('%s').", strings.Join(api.Formats, "', '")),
-							MarkdownDescription: fmt.Sprintf("Format of the input data (`%s`).", strings.Join(api.Formats, "`, `")),
-							Required:            true,
-							Validators:          []validator.String{stringvalidator.OneOf(api.Formats...)},
-						},
-						"json_hints": schema.ListNestedAttribute{
-							Description: "Ingestion hints for JSON input.",
-							Optional:    true,
-							NestedObject: schema.NestedAttributeObject{
-								Attributes: map[string]schema.Attribute{
-									"field": schema.StringAttribute{
-										Description: "Field name.",
-										Required:    true,
-									},
-									"hints": schema.ListAttribute{
-										Description: "Hints.",
-										Required:    true,
-										ElementType: types.StringType,
-									},
-								},
-							},
-							Validators: []validator.List{tableSupportedFormatsValidator{filterFormats("json")}},
-						},
-						"csv_hints": schema.SingleNestedAttribute{
-							Description: "Ingestion hints for CSV input.",
-							Optional:    true,
-							Attributes: map[string]schema.Attribute{
-								"separator": schema.StringAttribute{
-									Description:         "specify a custom separator (defaults to ',').",
-									MarkdownDescription: "specify a custom separator (defaults to `,`).",
-									Optional:            true,
-									Validators:          []validator.String{stringvalidator.LengthBetween(1, 1)},
-								},
-								"skip_records":   skipRecords,
-								"missing_values": missingValues,
-								"fields":         fields,
-							},
-							Validators: []validator.Object{tableSupportedFormatsValidator{filterFormats("csv")}},
-						},
-						"tsv_hints": schema.SingleNestedAttribute{
-							Description: "Ingestion hints for TSV input.",
-							Optional:    true,
-							Attributes: map[string]schema.Attribute{
-								"skip_records":   skipRecords,
-								"missing_values": missingValues,
-								"fields":         fields,
-							},
-							Validators: []validator.Object{tableSupportedFormatsValidator{filterFormats("tsv")}},
-						},
-					},
+					Attributes: inputAttributes,
 				},
 			},
 			"partitions": schema.ListNestedAttribute{
 				Description: "Synthetic field that is generated from parts of an input URI and used to partition table data..",
 				Optional:    true,
+				Computed:    true,
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"field": schema.StringAttribute{
@@ -236,6 +282,7 @@ func (r *tableResource) Schema(ctx context.Context, req resource.SchemaRequest,
 			"retention_policy": schema.SingleNestedAttribute{
 				Description: "Synthetic field that is generated from parts of an input URI and used to partition table data.",
 				Optional:    true,
+				Computed:    true,
 				Attributes: map[string]schema.Attribute{
 					"field": schema.StringAttribute{
 						Description: "Path expression for the field used to determine the age of a record for the purpose of the data retention policy. Currently only timestamp fields are supported.",
@@ -255,6 +302,7 @@ func (r *tableResource) Schema(ctx context.Context, req resource.SchemaRequest,
 			"beta_features": schema.ListAttribute{
 				Description: "List of feature flags that can be used to turn on features for beta-testing.",
 				Optional:    true,
+				Computed:    true,
 				ElementType: types.StringType,
 			},
 			"skip_backfill": schema.BoolAttribute{
@@ -263,6 +311,54 @@ func (r *tableResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Computed:      true,
 				PlanModifiers: []planmodifier.Bool{BoolDefaultValue(false)},
 			},
+			"definition_json": schema.StringAttribute{
+				Description: "Table definition as a JSON document (i.e. the contents of a 'definition.json' file). " +
+					"It is sent as-is and can't be combined with the structured attributes ('inputs', 'partitions', ...), " +
+					"which are populated from the definition instead. Key ordering and whitespace are ignored when comparing.",
+				MarkdownDescription: "Table definition as a JSON document (i.e. the contents of a `definition.json` file). " +
+					"It is sent as-is and can't be combined with the structured attributes (`inputs`, `partitions`, ...), " +
+					"which are populated from the definition instead. Key ordering and whitespace are ignored when comparing.",
+				CustomType: model.JSONType{},
+				Optional:   true,
+				Computed:   true,
+			},
+			"extra_json": schema.StringAttribute{
+				Description: "JSON object with the fields of the table definition that aren't modeled by this resource. " +
+					"These fields are retained when the table is updated. Extra fields of an input are listed in the " +
+					"'input' array, together with the input's pattern.",
+				MarkdownDescription: "JSON object with the fields of the table definition that aren't modeled by this resource. " +
+					"These fields are retained when the table is updated. Extra fields of an input are listed in the " +
+					"`input` array, together with the input's pattern.",
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"wait_for_index": schema.BoolAttribute{
+				Description: "Wait until the table has an index (i.e. the first objects have been ingested) when it is " +
+					"created or updated, so dependent resources don't race the first ingestion. The wait is limited " +
+					"by the create and update timeouts.",
+				Optional: true,
+			},
+			"has_definition": schema.BoolAttribute{
+				Description:   "Indicates whether the table has a definition.",
+				Computed:      true,
+				PlanModifiers: []planmodifier.Bool{boolplanmodifier.UseStateForUnknown()},
+			},
+			"has_index": schema.BoolAttribute{
+				Description: "Indicates whether the table has an index (i.e. objects have been ingested).",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"input": schema.ListNestedBlock{
+				Description: "Input definition using the block syntax of older configurations. It is an alternative " +
+					"to the 'inputs' attribute and can't be combined with it.",
+				MarkdownDescription: "Input definition using the block syntax of older configurations. It is an alternative " +
+					"to the `inputs` attribute and can't be combined with it.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: inputAttributes,
+				},
+			},
+			"timeouts": timeoutsBlock(ctx),
 		},
 	}
 }
@@ -274,7 +370,83 @@ func (r *tableResource) Configure(ctx context.Context, req resource.ConfigureReq
 	r.client = req.ProviderData.(*api.Client)
 }
 
+func (r *tableResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	validators := []resource.ConfigValidator{tableInputsValidator{}}
+	for _, name := range tableDefinitionAttributes {
+		if name == "inputs" {
+			// checked by the table inputs validator
+			continue
+		}
+		validators = append(validators, resourcevalidator.Conflicting(path.MatchRoot("definition_json"), path.MatchRoot(name)))
+	}
+	return validators
+}
+
+func (r *tableResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		// resource is destroyed
+		return
+	}
+
+	var definitionJSON, stateDefinitionJSON model.JSONValue
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("definition_json"), &definitionJSON)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("definition_json"), &stateDefinitionJSON)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if definitionJSON.IsNull() {
+		// the structured attributes are used, so the
+		// computed attributes follow the configuration
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("definition_json"), model.NewJSONNull())...)
+		var input types.List
+		resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("input"), &input)...)
+		if len(input.Elements()) > 0 {
+			// the input blocks are the inputs
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("inputs"), input)...)
+		}
+		for _, name := range tableDefinitionAttributes {
+			var value attr.Value
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name), &value)...)
+			if value != nil && value.IsNull() && name != "skip_backfill" && (name != "inputs" || len(input.Elements()) == 0) {
+				resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), value)...)
+			}
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		resp.Diagnostics.Append(keepCanonicalState(ctx, req.Config, req.State, &resp.Plan)...)
+		return
+	}
+
+	attributes := append(slices.Clip(tableDefinitionAttributes), "extra_json")
+	if definitionJSON.IsUnknown() || stateDefinitionJSON.IsNull() || !model.JSONEqual(definitionJSON.ValueString(), stateDefinitionJSON.ValueString()) {
+		// the structured attributes are derived from the new definition
+		tflog.Debug(ctx, "Table definition JSON changed")
+		for _, name := range attributes {
+			resp.Diagnostics.Append(setUnknown(ctx, &resp.Plan, path.Root(name))...)
+		}
+		return
+	}
+
+	// the definition is semantically unchanged, so keep the
+	// current state to prevent a diff on formatting changes
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("definition_json"), stateDefinitionJSON)...)
+	for _, name := range attributes {
+		var value attr.Value
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root(name), &value)...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), value)...)
+	}
+}
+
 func (r *tableResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		// the prior state is kept until the provider is
+		// configured (see providerdata.CheckClient)
+		return
+	}
 
 	var data tableResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -282,12 +454,16 @@ func (r *tableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 4 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID),
-		)
+	ctx, cancel := withTimeout(ctx, "read", data.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 4, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
@@ -295,7 +471,7 @@ func (r *tableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 	database := parts[2]
 	table := parts[3]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -303,17 +479,14 @@ func (r *tableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
-	tableDescription, err := r.client.Table(ctx, region, database, table)
+	tableDescription, etag, err := client.Table(ctx, region, database, table)
 	if err != nil {
-		if err == api.ErrNotFound {
+		if errors.Is(err, api.ErrNotFound) {
+			tflog.Debug(ctx, "Table not found, removing from state", map[string]any{"region": region, "database": database, "table": table})
 			resp.State.RemoveResource(ctx)
 			return
 		}
@@ -324,7 +497,7 @@ func (r *tableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	err = json.Unmarshal(tableDescription, &data)
+	err = data.setDefinition(tableDescription)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot decode table configuration",
@@ -332,8 +505,42 @@ func (r *tableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		)
 		return
 	}
+	if len(data.Input) > 0 {
+		// the inputs are configured using input blocks
+		data.Input = slices.Clone(data.Inputs)
+	}
+	if data.Input == nil {
+		data.Input = []model.TableInputModel{}
+	}
+
+	extraJSON, err := model.TableExtraFields(tableDescription)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Cannot decode table configuration",
+			fmt.Sprintf("Unable to decode tenant table configuration of table %s:%s in region %s: %v", database, table, region, err.Error()),
+		)
+		return
+	}
+	data.ExtraJSON = extraJSONValue(extraJSON)
+	if !data.DefinitionJSON.IsNull() && !sameDefinition([]byte(data.DefinitionJSON.ValueString()), tableDescription) {
+		// the definition was modified outside of Terraform
+		data.DefinitionJSON = model.NewJSONValue(string(tableDescription))
+	}
+
+	tableInfo, err := client.TableInfo(ctx, region, database, table)
+	if err != nil && !errors.Is(err, api.ErrNotFound) {
+		resp.Diagnostics.AddError(
+			"Cannot get table info",
+			fmt.Sprintf("Unable to get table info of table %s:%s in region %s: %v", database, table, region, err.Error()),
+		)
+		return
+	}
+	data.setTableInfo(tableInfo)
+
+	setETag(ctx, resp.Private, etag, &resp.Diagnostics)
 
 	data.ID = types.StringValue(fmt.Sprintf("%s/%s/%s/%s", tenantInfo.TenantID, region, database, table))
+	data.TenantID = types.StringValue(tenantInfo.TenantID)
 	data.Region = types.StringValue(region)
 	data.Database = types.StringValue(database)
 	data.Table = &table
@@ -344,15 +551,27 @@ func (r *tableResource) Read(ctx context.Context, req resource.ReadRequest, resp
 }
 
 func (r *tableResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data tableResourceModel
 
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(getTablePlan(ctx, req.Plan, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := withTimeout(ctx, "create", data.Timeouts.Create, defaultCreateTimeout, &resp.Diagnostics)
+	defer cancel()
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
 	region := data.Region.ValueString()
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -366,31 +585,45 @@ func (r *tableResource) Create(ctx context.Context, req resource.CreateRequest,
 	}
 
 	database, table := data.Database.ValueString(), *data.Table
-	if err = r.writeTable(ctx, data, region, database, table, &resp.Diagnostics); err != nil {
+	etag, err := writeTable(ctx, client, &data, region, database, table, "", &resp.Diagnostics)
+	if err != nil {
 		return
 	}
+	setETag(ctx, resp.Private, etag, &resp.Diagnostics)
 
 	data.ID = types.StringValue(fmt.Sprintf("%s/%s/%s/%s", tenantInfo.TenantID, region, database, table))
+	data.TenantID = types.StringValue(tenantInfo.TenantID)
 	data.Region = types.StringValue(region)
 	data.Location = types.StringValue(fmt.Sprintf("%s/db/%s/%s/", tenantInfo.Regions[region].Bucket, database, table))
 
+	// the state is saved, even if the index isn't ready in time,
+	// so the table is tainted instead of being orphaned
+	refreshTableInfo(ctx, client, &data, region, database, table, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *tableResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data tableResourceModel
 
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(getTablePlan(ctx, req.Plan, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 4 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID),
-		)
+	ctx, cancel := withTimeout(ctx, "update", data.Timeouts.Update, defaultUpdateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 4, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
@@ -398,7 +631,7 @@ func (r *tableResource) Update(ctx context.Context, req resource.UpdateRequest,
 	database := parts[2]
 	table := parts[3]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -406,24 +639,27 @@ func (r *tableResource) Update(ctx context.Context, req resource.UpdateRequest,
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
-	if err = r.writeTable(ctx, data, region, database, table, &resp.Diagnostics); err != nil {
+	etag, err := writeTable(ctx, client, &data, region, database, table, getETag(ctx, req.Private, &resp.Diagnostics), &resp.Diagnostics)
+	if err != nil {
 		return
 	}
+	setETag(ctx, resp.Private, etag, &resp.Diagnostics)
 
 	data.Location = types.StringValue(fmt.Sprintf("%s/db/%s/%s/", tenantInfo.Regions[region].Bucket, database, table))
 
+	refreshTableInfo(ctx, client, &data, region, database, table, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *tableResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data tableResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -431,12 +667,16 @@ func (r *tableResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 4 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID),
-		)
+	ctx, cancel := withTimeout(ctx, "delete", data.Timeouts.Delete, defaultDeleteTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 4, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
@@ -444,7 +684,7 @@ func (r *tableResource) Delete(ctx context.Context, req resource.DeleteRequest,
 	database := parts[2]
 	table := parts[3]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -452,15 +692,11 @@ func (r *tableResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
-	err = r.client.DeleteTable(ctx, region, database, table, true)
+	err = client.DeleteTable(ctx, region, database, table, true)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot delete table",
@@ -471,8 +707,11 @@ func (r *tableResource) Delete(ctx context.Context, req resource.DeleteRequest,
 }
 
 func (r *tableResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importState(ctx, r.client, 4, resolveTableID, req, resp)
+}
+
+func (r *tableResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return stateUpgraders(tableSchemaUpgrades())
 }
 
 func filterFormats(format string) (ff []string) {
@@ -488,8 +727,39 @@ func filterFormats(format string) (ff []string) {
 	return ff
 }
 
-func (r *tableResource) writeTable(ctx context.Context, data tableResourceModel, region, database, table string, diags *diag.Diagnostics) error {
-	copy := data
+// writeTable writes the table definition and returns its new
+// entity tag. Fields of the current table definition that aren't
+// modeled by the resource are merged into the new definition, so
+// they are retained. When etag isn't empty, then the definition is
+// only written when it wasn't modified since it was last read.
+func writeTable(ctx context.Context, client *api.Client, data *tableResourceModel, region, database, table, etag string, diags *diag.Diagnostics) (string, error) {
+	if !data.DefinitionJSON.IsNull() {
+		// the JSON definition is sent as-is
+		tableBytes := []byte(data.DefinitionJSON.ValueString())
+		extraJSON, err := model.TableExtraFields(tableBytes)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("definition_json"),
+				"Cannot decode table configuration",
+				fmt.Sprintf("Unable to decode table definition JSON: %v", err.Error()),
+			)
+			return "", err
+		}
+		newETag, err := client.SetTable(ctx, region, database, table, tableBytes, etag)
+		if err != nil {
+			addWriteError(
+				diags,
+				"Cannot create table",
+				fmt.Sprintf("Unable to create table %s/%s in region %s", database, table, region),
+				err,
+			)
+			return "", err
+		}
+		data.ExtraJSON = extraJSONValue(extraJSON)
+		return newETag, nil
+	}
+
+	copy := *data
 	if copy.SkipBackfill != nil && !*copy.SkipBackfill {
 		// this prevents writing the `false` value
 		copy.SkipBackfill = nil
@@ -500,21 +770,284 @@ func (r *tableResource) writeTable(ctx context.Context, data tableResourceModel,
 			"Cannot encode table configuration",
 			fmt.Sprintf("Unable to encode table configuration in region %s: %v", region, err.Error()),
 		)
-		return err
+		return "", err
 	}
 
-	err = r.client.SetTable(ctx, region, database, table, tableBytes)
+	var extraJSON []byte
+	currentTableBytes, currentETag, err := client.Table(ctx, region, database, table)
+	if err == nil {
+		extraJSON, err = model.TableExtraFields(currentTableBytes)
+		if etag == "" {
+			// prevent overwriting modifications since the
+			// current definition was read
+			etag = currentETag
+		}
+	} else if errors.Is(err, api.ErrNotFound) {
+		err = nil
+	}
 	if err != nil {
 		diags.AddError(
+			"Cannot get table configuration",
+			fmt.Sprintf("Unable to get current table configuration of table %s/%s in region %s: %v", database, table, region, err.Error()),
+		)
+		return "", err
+	}
+	if extraJSON != nil {
+		tflog.Debug(ctx, "Retaining extra table definition fields", map[string]any{"extra_json": string(extraJSON)})
+		tableBytes, err = model.MergeTableExtraFields(tableBytes, extraJSON)
+		if err != nil {
+			diags.AddError(
+				"Cannot encode table configuration",
+				fmt.Sprintf("Unable to merge extra fields into table configuration in region %s: %v", region, err.Error()),
+			)
+			return "", err
+		}
+	}
+
+	newETag, err := client.SetTable(ctx, region, database, table, tableBytes, etag)
+	if err != nil {
+		addWriteError(
+			diags,
 			"Cannot create table",
-			fmt.Sprintf("Unable to create table %s/%s in region %s: %v", database, table, region, err.Error()),
+			fmt.Sprintf("Unable to create table %s/%s in region %s", database, table, region),
+			err,
 		)
-		return err
+		return "", err
 	}
 
+	data.ExtraJSON = extraJSONValue(extraJSON)
+	return newETag, nil
+}
+
+// tableIndexPollInterval is the interval at which the table
+// info is obtained while waiting for the table's index.
+var tableIndexPollInterval = 10 * time.Second
+
+// refreshTableInfo sets the computed table info attributes after
+// the table has been written. When wait_for_index is set, then it
+// waits until the table has an index (or the context is done).
+func refreshTableInfo(ctx context.Context, client *api.Client, data *tableResourceModel, region, database, table string, diags *diag.Diagnostics) {
+	for {
+		tableInfo, err := client.TableInfo(ctx, region, database, table)
+		if err != nil && !errors.Is(err, api.ErrNotFound) {
+			diags.AddError(
+				"Cannot get table info",
+				fmt.Sprintf("Unable to get table info of table %s/%s in region %s: %v", database, table, region, err.Error()),
+			)
+			return
+		}
+		data.setTableInfo(tableInfo)
+		if !data.WaitForIndex.ValueBool() || data.HasIndex.ValueBool() {
+			return
+		}
+
+		tflog.Debug(ctx, "Waiting for table index", map[string]any{"region": region, "database": database, "table": table})
+		t := time.NewTimer(tableIndexPollInterval)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			diags.AddError(
+				"Table index not ready",
+				fmt.Sprintf("Table %s/%s in region %s has no index yet: %v", database, table, region, ctx.Err()),
+			)
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// setTableInfo sets the computed table info attributes. The table
+// info is nil when the table isn't listed (yet).
+func (data *tableResourceModel) setTableInfo(tableInfo *api.TableInfo) {
+	if tableInfo == nil {
+		tableInfo = &api.TableInfo{}
+	}
+	data.HasDefinition = types.BoolValue(tableInfo.HasDefinition)
+	data.HasIndex = types.BoolValue(tableInfo.HasIndex)
+}
+
+// setDefinition sets the structured attributes from the
+// table definition.
+func (data *tableResourceModel) setDefinition(definition []byte) error {
+	data.Inputs = nil
+	data.Partitions = nil
+	data.RetentionPolicy = nil
+	data.BetaFeatures = nil
+	data.SkipBackfill = nil
+	if err := json.Unmarshal(definition, data); err != nil {
+		return err
+	}
+	if data.SkipBackfill == nil {
+		data.SkipBackfill = ptr(false)
+	}
+	for i := range data.Inputs {
+		data.Inputs[i].Normalize()
+	}
 	return nil
 }
 
+// canonicalDefinition returns the canonical form of the
+// structured table definition, so it can be compared.
+func (data *tableResourceModel) canonicalDefinition() ([]byte, error) {
+	inputs, err := model.CanonicalTableInputs(data.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	copy := *data
+	copy.Inputs = inputs
+	if copy.SkipBackfill != nil && !*copy.SkipBackfill {
+		copy.SkipBackfill = nil
+	}
+	return json.Marshal(&copy)
+}
+
+// sameDefinition returns whether both table definitions are the
+// same. The Sneller API may reorder the fields, fill in defaults or
+// strip the leading dot of formats, so the definitions are equal
+// when their canonical forms (and extra fields) are the same.
+func sameDefinition(a, b []byte) bool {
+	if model.JSONEqual(string(a), string(b)) {
+		return true
+	}
+	var dataA, dataB tableResourceModel
+	if dataA.setDefinition(a) != nil || dataB.setDefinition(b) != nil {
+		return false
+	}
+	canonicalA, errA := dataA.canonicalDefinition()
+	canonicalB, errB := dataB.canonicalDefinition()
+	if errA != nil || errB != nil || !bytes.Equal(canonicalA, canonicalB) {
+		return false
+	}
+	extraA, errA := model.TableExtraFields(a)
+	extraB, errB := model.TableExtraFields(b)
+	return errA == nil && errB == nil && model.JSONEqual(string(extraA), string(extraB))
+}
+
+// keepCanonicalState prevents perpetual diffs of the structured
+// table definition. A definition that is read back from the
+// Sneller API may differ from the configuration (formats with a
+// leading dot, reordered hints or omitted defaults), so both
+// sides are compared in their canonical form. When they are the
+// same, then the definition keeps its current state (Terraform
+// accepts the prior value of a configured attribute as well).
+func keepCanonicalState(ctx context.Context, config tfsdk.Config, state tfsdk.State, plan *tfsdk.Plan) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if state.Raw.IsNull() {
+		return diags
+	}
+
+	var configData, stateData, planData tableResourceModel
+	if config.Get(ctx, &configData).HasError() || plan.Get(ctx, &planData).HasError() {
+		// the definition isn't known yet
+		return diags
+	}
+	diags.Append(state.Get(ctx, &stateData)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	planDefinition, err := planData.canonicalDefinition()
+	if err != nil {
+		return diags
+	}
+	stateDefinition, err := stateData.canonicalDefinition()
+	if err != nil || !bytes.Equal(planDefinition, stateDefinition) {
+		return diags
+	}
+	if len(configData.Input) != len(stateData.Input) {
+		// the input blocks are added or removed
+		return diags
+	}
+
+	tflog.Debug(ctx, "Table definition is unchanged", map[string]any{"definition": string(stateDefinition)})
+	planData.Inputs = stateData.Inputs
+	planData.Input = stateData.Input
+	planData.Partitions = stateData.Partitions
+	planData.RetentionPolicy = stateData.RetentionPolicy
+	planData.BetaFeatures = stateData.BetaFeatures
+	planData.SkipBackfill = stateData.SkipBackfill
+	planData.Location = stateData.Location
+	planData.ExtraJSON = stateData.ExtraJSON
+
+	// the index status is only refreshed when the table is
+	// updated, so it's kept when nothing else changes
+	unchanged := *plan
+	hasIndex := planData.HasIndex
+	planData.HasIndex = stateData.HasIndex
+	diags.Append(unchanged.Set(ctx, &planData)...)
+	if diags.HasError() {
+		return diags
+	}
+	if unchanged.Raw.Equal(state.Raw) {
+		*plan = unchanged
+		return diags
+	}
+	planData.HasIndex = hasIndex
+	diags.Append(plan.Set(ctx, &planData)...)
+	return diags
+}
+
+// getTablePlan reads the plan into the model. When the table
+// is defined using definition_json, then the structured
+// attributes are unknown and they are derived from the JSON
+// definition instead.
+func getTablePlan(ctx context.Context, plan tfsdk.Plan, data *tableResourceModel) diag.Diagnostics {
+	var definitionJSON model.JSONValue
+	diags := plan.GetAttribute(ctx, path.Root("definition_json"), &definitionJSON)
+	if diags.HasError() {
+		return diags
+	}
+	if definitionJSON.IsNull() {
+		return plan.Get(ctx, data)
+	}
+
+	diags.Append(plan.GetAttribute(ctx, path.Root("id"), &data.ID)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("tenant_id"), &data.TenantID)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("region"), &data.Region)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("database"), &data.Database)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("table"), &data.Table)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("location"), &data.Location)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("input"), &data.Input)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("wait_for_index"), &data.WaitForIndex)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("has_definition"), &data.HasDefinition)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("has_index"), &data.HasIndex)...)
+	diags.Append(plan.GetAttribute(ctx, path.Root("timeouts"), &data.Timeouts)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	data.DefinitionJSON = definitionJSON
+	if err := data.setDefinition([]byte(definitionJSON.ValueString())); err != nil {
+		diags.AddAttributeError(
+			path.Root("definition_json"),
+			"Cannot decode table configuration",
+			fmt.Sprintf("Unable to decode table definition JSON: %v", err.Error()),
+		)
+	}
+	return diags
+}
+
+// setUnknown marks the attribute as unknown in the plan.
+func setUnknown(ctx context.Context, plan *tfsdk.Plan, p path.Path) diag.Diagnostics {
+	attrType, diags := plan.Schema.TypeAtPath(ctx, p)
+	if diags.HasError() {
+		return diags
+	}
+	value, err := attrType.ValueFromTerraform(ctx, tftypes.NewValue(attrType.TerraformType(ctx), tftypes.UnknownValue))
+	if err != nil {
+		diags.AddAttributeError(p, "Cannot set unknown value", err.Error())
+		return diags
+	}
+	return plan.SetAttribute(ctx, p, value)
+}
+
+func extraJSONValue(extraJSON []byte) types.String {
+	if extraJSON == nil {
+		return types.StringNull()
+	}
+	return types.StringValue(string(extraJSON))
+}
+
 var _ validator.List = &tableSupportedFormatsValidator{}
 var _ validator.Object = &tableSupportedFormatsValidator{}
 
@@ -535,7 +1068,7 @@ func (v tableSupportedFormatsValidator) ValidateList(ctx context.Context, req va
 		return
 	}
 
-	diags := v.validate(ctx, req.Config, req.PathExpression)
+	diags := v.validate(ctx, req.Config, req.Path, req.PathExpression)
 	resp.Diagnostics.Append(diags...)
 }
 
@@ -544,11 +1077,11 @@ func (v tableSupportedFormatsValidator) ValidateObject(ctx context.Context, req
 		return
 	}
 
-	diags := v.validate(ctx, req.Config, req.PathExpression)
+	diags := v.validate(ctx, req.Config, req.Path, req.PathExpression)
 	resp.Diagnostics.Append(diags...)
 }
 
-func (v tableSupportedFormatsValidator) validate(ctx context.Context, config tfsdk.Config, pathExpr path.Expression) diag.Diagnostics {
+func (v tableSupportedFormatsValidator) validate(ctx context.Context, config tfsdk.Config, p path.Path, pathExpr path.Expression) diag.Diagnostics {
 	fa := pathExpr.AtParent().AtName("format")
 	paths, diags := config.PathMatches(ctx, fa)
 	if diags.HasError() {
@@ -560,17 +1093,72 @@ func (v tableSupportedFormatsValidator) validate(ctx context.Context, config tfs
 		return diags
 	}
 
-	var formatString types.String
-	if diags := tfsdk.ValueAs(ctx, formatAttr, &formatString); diags.HasError() {
+	var formatValue model.FormatValue
+	if diags := tfsdk.ValueAs(ctx, formatAttr, &formatValue); diags.HasError() {
 		return diags
 	}
+	if formatValue.IsNull() || formatValue.IsUnknown() {
+		return diag.Diagnostics{}
+	}
 
-	format := formatString.ValueString()
+	format := formatValue.ValueString()
 	if !slices.Contains(v.formats, format) {
 		return diag.Diagnostics{
-			diag.NewErrorDiagnostic("not valid for format", fmt.Sprintf("property %q cannot be set for format %q", pathExpr, format)),
+			diag.NewAttributeErrorDiagnostic(p, "not valid for format", fmt.Sprintf("property %q cannot be set for format %q", pathExpr, format)),
 		}
 	}
 
 	return diag.Diagnostics{}
 }
+
+// tableInputsValidator ensures that the table definition is
+// specified in exactly one style: the inputs attribute, the input
+// blocks (as used by older configurations) or definition_json.
+type tableInputsValidator struct{}
+
+func (v tableInputsValidator) Description(_ context.Context) string {
+	return "Exactly one of 'inputs', 'input' blocks or 'definition_json' must be specified"
+}
+
+func (v tableInputsValidator) MarkdownDescription(_ context.Context) string {
+	return "Exactly one of `inputs`, `input` blocks or `definition_json` must be specified"
+}
+
+func (v tableInputsValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var inputs, input types.List
+	var definitionJSON model.JSONValue
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("inputs"), &inputs)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("input"), &input)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("definition_json"), &definitionJSON)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if inputs.IsUnknown() || input.IsUnknown() || definitionJSON.IsUnknown() {
+		return
+	}
+
+	var styles []string
+	if !inputs.IsNull() {
+		styles = append(styles, "inputs")
+	}
+	if len(input.Elements()) > 0 {
+		styles = append(styles, "input")
+	}
+	if !definitionJSON.IsNull() {
+		styles = append(styles, "definition_json")
+	}
+	switch len(styles) {
+	case 0:
+		resp.Diagnostics.AddError(
+			"Missing table definition",
+			"The table definition must be specified using the 'inputs' attribute, 'input' blocks or 'definition_json'.",
+		)
+	case 1:
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root(styles[1]),
+			"Conflicting table definition",
+			fmt.Sprintf("The table definition must be specified in one style only, but %s are combined.", strings.Join(styles, " and ")),
+		)
+	}
+}
@@ -0,0 +1,72 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/snellertest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestRefreshTableInfo(t *testing.T) {
+	srv := snellertest.NewServer()
+	defer srv.Close()
+
+	apiURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &api.Client{
+		Token:         srv.Token,
+		DefaultRegion: api.DefaultSnellerRegion,
+		ApiURL:        apiURL,
+	}
+
+	defer func(d time.Duration) { tableIndexPollInterval = d }(tableIndexPollInterval)
+	tableIndexPollInterval = 10 * time.Millisecond
+
+	ctx := context.Background()
+	region := api.DefaultSnellerRegion
+	if err := client.SetBucket(ctx, region, "s3://cache-bucket", "arn:aws:iam::111111111111:role/test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SetTable(ctx, region, "db", "table", []byte(`{"input":[]}`), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var diags diag.Diagnostics
+	data := tableResourceModel{WaitForIndex: types.BoolValue(false)}
+	refreshTableInfo(ctx, client, &data, region, "db", "table", &diags)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+	if !data.HasDefinition.ValueBool() || data.HasIndex.ValueBool() {
+		t.Errorf("unexpected table info (definition: %v, index: %v)", data.HasDefinition, data.HasIndex)
+	}
+
+	// the wait is bounded by the context
+	data.WaitForIndex = types.BoolValue(true)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	refreshTableInfo(timeoutCtx, client, &data, region, "db", "table", &diags)
+	cancel()
+	if !diags.HasError() || data.HasIndex.ValueBool() {
+		t.Errorf("expected error diagnostic, got %v", diags)
+	}
+
+	diags = nil
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		srv.SetTableIndexed(region, "db", "table")
+	}()
+	refreshTableInfo(ctx, client, &data, region, "db", "table", &diags)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+	if !data.HasDefinition.ValueBool() || !data.HasIndex.ValueBool() {
+		t.Errorf("unexpected table info (definition: %v, index: %v)", data.HasDefinition, data.HasIndex)
+	}
+}
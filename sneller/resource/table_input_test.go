@@ -0,0 +1,152 @@
+package resource
+
+import (
+	"context"
+	"terraform-provider-sneller/sneller/api"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"golang.org/x/exp/slices"
+)
+
+// testTableConfig returns a table configuration with the given
+// attributes (all other attributes are null).
+func testTableConfig(t *testing.T, values map[string]tftypes.Value) tfsdk.Config {
+	t.Helper()
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	(&tableResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	attrs := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		if v, ok := values[name]; ok {
+			attrs[name] = v
+		} else if name == "input" {
+			attrs[name] = tftypes.NewValue(attrType, []tftypes.Value{})
+		} else {
+			attrs[name] = tftypes.NewValue(attrType, nil)
+		}
+	}
+	return tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, attrs)}
+}
+
+func TestTableInputsValidator(t *testing.T) {
+	ctx := context.Background()
+	config := testTableConfig(t, nil)
+	objectType := config.Raw.Type().(tftypes.Object)
+	inputsType := objectType.AttributeTypes["inputs"].(tftypes.List)
+	inputType := inputsType.ElementType.(tftypes.Object)
+
+	inputAttrs := make(map[string]tftypes.Value, len(inputType.AttributeTypes))
+	for name, attrType := range inputType.AttributeTypes {
+		inputAttrs[name] = tftypes.NewValue(attrType, nil)
+	}
+	inputAttrs["pattern"] = tftypes.NewValue(tftypes.String, "s3://bucket/*.ndjson")
+	inputAttrs["format"] = tftypes.NewValue(tftypes.String, "json")
+	inputs := tftypes.NewValue(inputsType, []tftypes.Value{tftypes.NewValue(inputType, inputAttrs)})
+	definitionJSON := tftypes.NewValue(tftypes.String, `{"input":[]}`)
+
+	tests := []struct {
+		name   string
+		values map[string]tftypes.Value
+		ok     bool
+	}{
+		{"none", nil, false},
+		{"inputs", map[string]tftypes.Value{"inputs": inputs}, true},
+		{"input", map[string]tftypes.Value{"input": inputs}, true},
+		{"definition_json", map[string]tftypes.Value{"definition_json": definitionJSON}, true},
+		{"inputs-and-input", map[string]tftypes.Value{"inputs": inputs, "input": inputs}, false},
+		{"input-and-definition_json", map[string]tftypes.Value{"input": inputs, "definition_json": definitionJSON}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := resource.ValidateConfigRequest{Config: testTableConfig(t, tt.values)}
+			var resp resource.ValidateConfigResponse
+			tableInputsValidator{}.ValidateResource(ctx, req, &resp)
+			if ok := !resp.Diagnostics.HasError(); ok != tt.ok {
+				t.Errorf("expected valid %v, got %v", tt.ok, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+// nullValues returns the attributes of the object type with null
+// values, except for the given values.
+func nullValues(objectType tftypes.Object, values map[string]tftypes.Value) map[string]tftypes.Value {
+	attrs := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attrType := range objectType.AttributeTypes {
+		if v, ok := values[name]; ok {
+			attrs[name] = v
+		} else {
+			attrs[name] = tftypes.NewValue(attrType, nil)
+		}
+	}
+	return attrs
+}
+
+func TestTableHintsFormats(t *testing.T) {
+	ctx := context.Background()
+	server := testProviderServer(t, nil)
+
+	objectType := testTableConfig(t, nil).Raw.Type().(tftypes.Object)
+	inputsType := objectType.AttributeTypes["inputs"].(tftypes.List)
+	inputType := inputsType.ElementType.(tftypes.Object)
+	jsonHintsType := inputType.AttributeTypes["json_hints"].(tftypes.List)
+	jsonHintType := jsonHintsType.ElementType.(tftypes.Object)
+	csvHintsType := inputType.AttributeTypes["csv_hints"].(tftypes.Object)
+	tsvHintsType := inputType.AttributeTypes["tsv_hints"].(tftypes.Object)
+
+	hints := map[string]tftypes.Value{
+		"json": tftypes.NewValue(jsonHintsType, []tftypes.Value{
+			tftypes.NewValue(jsonHintType, nullValues(jsonHintType, map[string]tftypes.Value{
+				"path":  tftypes.NewValue(tftypes.String, "ts"),
+				"hints": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, []tftypes.Value{tftypes.NewValue(tftypes.String, "datetime")}),
+			})),
+		}),
+		"csv": tftypes.NewValue(csvHintsType, nullValues(csvHintsType, map[string]tftypes.Value{
+			"separator": tftypes.NewValue(tftypes.String, ";"),
+		})),
+		"tsv": tftypes.NewValue(tsvHintsType, nullValues(tsvHintsType, map[string]tftypes.Value{
+			"skip_records": tftypes.NewValue(tftypes.Number, 1),
+		})),
+	}
+
+	for _, format := range api.Formats {
+		for kind, hint := range hints {
+			t.Run(format+"/"+kind+"_hints", func(t *testing.T) {
+				input := tftypes.NewValue(inputType, nullValues(inputType, map[string]tftypes.Value{
+					"pattern":       tftypes.NewValue(tftypes.String, "s3://source/*"),
+					"format":        tftypes.NewValue(tftypes.String, format),
+					kind + "_hints": hint,
+				}))
+				config := testTableConfig(t, map[string]tftypes.Value{
+					"database": tftypes.NewValue(tftypes.String, "db"),
+					"table":    tftypes.NewValue(tftypes.String, "table"),
+					"inputs":   tftypes.NewValue(inputsType, []tftypes.Value{input}),
+				})
+				value, err := tfprotov6.NewDynamicValue(objectType, config.Raw)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp, err := server.ValidateResourceConfig(ctx, &tfprotov6.ValidateResourceConfigRequest{
+					TypeName: "sneller_table",
+					Config:   &value,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				ok := slices.Contains(filterFormats(kind), format)
+				if valid := len(resp.Diagnostics) == 0; valid != ok {
+					for _, d := range resp.Diagnostics {
+						t.Errorf("%s: %s", d.Summary, d.Detail)
+					}
+					t.Fatalf("expected valid %v", ok)
+				}
+			})
+		}
+	}
+}
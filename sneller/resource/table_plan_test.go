@@ -0,0 +1,278 @@
+package resource
+
+import (
+	"context"
+	"net/url"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/model"
+	"terraform-provider-sneller/sneller/snellertest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// testProvider is a provider with the table resource that uses
+// the given client.
+type testProvider struct {
+	client *api.Client
+}
+
+func (p *testProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "sneller"
+}
+
+func (p *testProvider) Schema(context.Context, provider.SchemaRequest, *provider.SchemaResponse) {}
+
+func (p *testProvider) Configure(_ context.Context, _ provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	resp.ResourceData = p.client
+}
+
+func (p *testProvider) Resources(context.Context) []func() resource.Resource {
+	return []func() resource.Resource{NewTableResource}
+}
+
+func (p *testProvider) DataSources(context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+// testProviderServer returns the configured server of the test
+// provider.
+func testProviderServer(t *testing.T, client *api.Client) tfprotov6.ProviderServer {
+	t.Helper()
+	ctx := context.Background()
+	server := providerserver.NewProtocol6(&testProvider{client: client})()
+	if _, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	providerConfig, err := tfprotov6.NewDynamicValue(tftypes.Object{}, tftypes.NewValue(tftypes.Object{}, map[string]tftypes.Value{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &providerConfig}); err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+// proposedNewState returns the proposed new state like Terraform
+// does: computed attributes that aren't configured keep their
+// prior value.
+func proposedNewState(ctx context.Context, s tfsdk.State, config, prior tftypes.Value) (tftypes.Value, error) {
+	return tftypes.Transform(config, func(p *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+		if !value.IsNull() || len(p.Steps()) == 0 {
+			return value, nil
+		}
+		attribute, err := s.Schema.AttributeAtTerraformPath(ctx, p)
+		if err != nil || !attribute.IsComputed() {
+			return value, nil
+		}
+		priorValue, _, err := tftypes.WalkAttributePath(prior, p)
+		if err != nil {
+			return value, nil
+		}
+		return priorValue.(tftypes.Value), nil
+	})
+}
+
+func TestTablePlanReadBack(t *testing.T) {
+	srv := snellertest.NewServer()
+	defer srv.Close()
+
+	apiURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &api.Client{
+		Token:         srv.Token,
+		DefaultRegion: api.DefaultSnellerRegion,
+		ApiURL:        apiURL,
+	}
+
+	// the definition as it's returned by the Sneller API (formats
+	// with a leading dot, sorted hints, a single hint as a string
+	// and the defaults of CSV fields)
+	ctx := context.Background()
+	region := api.DefaultSnellerRegion
+	if err := client.SetBucket(ctx, region, "s3://cache-bucket", "arn:aws:iam::111111111111:role/test"); err != nil {
+		t.Fatal(err)
+	}
+	definition := `{
+		"input": [
+			{"pattern": "s3://source/*.ndjson", "format": ".json", "hints": [{"path": "ts", "hints": ["datetime", "no_index"]}, {"path": "id", "hints": "string"}]},
+			{"pattern": "s3://source/*.csv", "format": ".csv", "hints": {"fields": [{"name": "ts", "type": "datetime", "allowEmpty": false, "noIndex": false}]}}
+		]
+	}`
+	if _, err := client.SetTable(ctx, region, "db", "table", []byte(definition), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	server := testProviderServer(t, client)
+
+	// refresh the imported state
+	imported := testTableConfig(t, map[string]tftypes.Value{
+		"id": tftypes.NewValue(tftypes.String, srv.TenantID+"/"+region+"/db/table"),
+	})
+	valueType := imported.Raw.Type()
+	currentState, err := tfprotov6.NewDynamicValue(valueType, imported.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readResp, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{TypeName: "sneller_table", CurrentState: &currentState})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readResp.Diagnostics) > 0 {
+		t.Fatalf("unexpected diagnostics %v", readResp.Diagnostics[0])
+	}
+	prior, err := readResp.NewState.Unmarshal(valueType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the configuration of the same definition
+	config := testTableConfig(t, map[string]tftypes.Value{
+		"database": tftypes.NewValue(tftypes.String, "db"),
+		"table":    tftypes.NewValue(tftypes.String, "table"),
+	})
+	var data tableResourceModel
+	if diags := config.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+	data.Inputs = []model.TableInputModel{
+		{
+			Pattern: "s3://source/*.ndjson",
+			Format:  ptr("json"),
+			JSONHints: []model.TableInputJSONHintModel{
+				{Path: "ts", Hints: model.Hints{"no_index", "datetime"}},
+				{Path: "id", Hints: model.Hints{"string"}},
+			},
+		},
+		{
+			Pattern: "s3://source/*.csv",
+			Format:  ptr("csv"),
+			CSVHints: &model.TableInputCSVHintModel{
+				Fields: []model.TableInputXSVHintsFieldModel{{Name: ptr("ts"), Type: ptr("datetime")}},
+			},
+		},
+	}
+
+	plan := func(t *testing.T) tftypes.Value {
+		t.Helper()
+		s := tfsdk.State{Schema: config.Schema, Raw: config.Raw}
+		if diags := s.Set(ctx, &data); diags.HasError() {
+			t.Fatalf("unexpected diagnostics %v", diags)
+		}
+		proposed, err := proposedNewState(ctx, s, s.Raw, prior)
+		if err != nil {
+			t.Fatal(err)
+		}
+		configValue, _ := tfprotov6.NewDynamicValue(valueType, s.Raw)
+		priorValue, _ := tfprotov6.NewDynamicValue(valueType, prior)
+		proposedValue, _ := tfprotov6.NewDynamicValue(valueType, proposed)
+		resp, err := server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+			TypeName:         "sneller_table",
+			PriorState:       &priorValue,
+			ProposedNewState: &proposedValue,
+			Config:           &configValue,
+			PriorPrivate:     readResp.Private,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Diagnostics) > 0 {
+			t.Fatalf("unexpected diagnostics %v", resp.Diagnostics[0])
+		}
+		planned, err := resp.PlannedState.Unmarshal(valueType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return planned
+	}
+
+	planned := plan(t)
+	if !planned.Equal(prior) {
+		diffs, _ := prior.Diff(planned)
+		for _, d := range diffs {
+			t.Errorf("planned change of %s: %v -> %v", d.Path, d.Value1, d.Value2)
+		}
+	}
+
+	// a modified definition is planned as configured
+	data.Inputs[0].Pattern = "s3://other/*.ndjson"
+	planned = plan(t)
+	pattern, _, _ := tftypes.WalkAttributePath(planned, tftypes.NewAttributePath().WithAttributeName("inputs").WithElementKeyInt(0).WithAttributeName("pattern"))
+	location, _, _ := tftypes.WalkAttributePath(planned, tftypes.NewAttributePath().WithAttributeName("location"))
+	if !pattern.(tftypes.Value).Equal(tftypes.NewValue(tftypes.String, "s3://other/*.ndjson")) || location.(tftypes.Value).IsKnown() {
+		t.Errorf("expected planned update, got %v", planned)
+	}
+}
+
+func TestTableReadDefinitionJSON(t *testing.T) {
+	srv := snellertest.NewServer()
+	defer srv.Close()
+
+	apiURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &api.Client{
+		Token:         srv.Token,
+		DefaultRegion: api.DefaultSnellerRegion,
+		ApiURL:        apiURL,
+	}
+
+	ctx := context.Background()
+	region := api.DefaultSnellerRegion
+	if err := client.SetBucket(ctx, region, "s3://cache-bucket", "arn:aws:iam::111111111111:role/test"); err != nil {
+		t.Fatal(err)
+	}
+	server := testProviderServer(t, client)
+
+	configured := `{"input": [{"pattern": "s3://source/*.ndjson", "format": "json"}], "skip_backfill": true}`
+	state := testTableConfig(t, map[string]tftypes.Value{
+		"id":              tftypes.NewValue(tftypes.String, srv.TenantID+"/"+region+"/db/table"),
+		"definition_json": tftypes.NewValue(tftypes.String, configured),
+	})
+	valueType := state.Raw.Type()
+	read := func(t *testing.T, definition string) string {
+		t.Helper()
+		if _, err := client.SetTable(ctx, region, "db", "table", []byte(definition), ""); err != nil {
+			t.Fatal(err)
+		}
+		currentState, err := tfprotov6.NewDynamicValue(valueType, state.Raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{TypeName: "sneller_table", CurrentState: &currentState})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Diagnostics) > 0 {
+			t.Fatalf("unexpected diagnostics %v", resp.Diagnostics[0])
+		}
+		newState, err := resp.NewState.Unmarshal(valueType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, _, _ := tftypes.WalkAttributePath(newState, tftypes.NewAttributePath().WithAttributeName("definition_json"))
+		var definitionJSON string
+		value.(tftypes.Value).As(&definitionJSON)
+		return definitionJSON
+	}
+
+	// the same definition as returned by the Sneller API
+	if got := read(t, `{"skip_backfill":true,"input":[{"format":".json","pattern":"s3://source/*.ndjson"}],"partitions":[]}`); got != configured {
+		t.Errorf("unexpected definition %s", got)
+	}
+	// a definition that was modified outside of Terraform
+	modified := `{"input":[{"pattern":"s3://other/*.ndjson","format":"json"}],"skip_backfill":true}`
+	if got := read(t, modified); got != modified {
+		t.Errorf("unexpected definition %s", got)
+	}
+}
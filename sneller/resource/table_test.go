@@ -2,6 +2,7 @@ package resource_test
 
 import (
 	"fmt"
+	"regexp"
 	"terraform-provider-sneller/sneller/acctest"
 	"terraform-provider-sneller/sneller/api"
 	"testing"
@@ -35,7 +36,7 @@ func TestAccResourceTable(t *testing.T) {
 								format     = "json"
 								json_hints = [
 									{
-										field = "path.to.value.a"
+										path  = "path.to.value.a"
 										hints = ["ignore"]
 									},{
 										field = "endTimestamp"
@@ -72,10 +73,12 @@ func TestAccResourceTable(t *testing.T) {
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.pattern", "s3://"+acctest.Bucket1Name+"/data/{tenant}/{yyyy}/{mm}/{dd}/*.ndjson"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.format", "json"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.0.path", "path.to.value.a"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.0.field", "path.to.value.a"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.0.hints.#", "1"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.0.hints.0", "ignore"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.1.field", "endTimestamp"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.1.path", "endTimestamp"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.1.hints.#", "2"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.1.hints.0", "no_index"),
 					resource.TestCheckResourceAttr(resourceName, "inputs.0.json_hints.1.hints.1", "RFC3339Nano"),
@@ -98,6 +101,13 @@ func TestAccResourceTable(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			// Import testing by database and table
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     acctest.DatabaseName + "/" + acctest.TableName,
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: baseConfig + `
@@ -136,3 +146,148 @@ func TestAccResourceTable(t *testing.T) {
 		},
 	})
 }
+
+func TestAccResourceTableDefinitionJSON(t *testing.T) {
+	resourceName := "sneller_table.test"
+	baseConfig := acctest.ProviderConfig + `
+		resource "sneller_tenant_region" "test" {
+			region   = "` + api.DefaultSnellerRegion + `"
+			bucket   = "` + acctest.Bucket1Name + `"
+			role_arn = "` + acctest.Role1ARN + `"
+		}`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: baseConfig + `
+					resource "sneller_table" "test" {
+						region   = sneller_tenant_region.test.region
+						database = "` + acctest.DatabaseName + `"
+						table    = "` + acctest.TableName + `"
+
+						definition_json = jsonencode({
+							input = [{
+								pattern = "s3://` + acctest.Bucket1Name + `/data/*.ndjson"
+								format  = "json"
+							}]
+							partitions = [{ field = "tenant" }]
+						})
+					}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", fmt.Sprintf("%s/%s/%s/%s", acctest.SnellerTenantID, api.DefaultSnellerRegion, acctest.DatabaseName, acctest.TableName)),
+					resource.TestCheckResourceAttrSet(resourceName, "definition_json"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.0.pattern", "s3://"+acctest.Bucket1Name+"/data/*.ndjson"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.0.format", "json"),
+					resource.TestCheckResourceAttr(resourceName, "partitions.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "partitions.0.field", "tenant"),
+					resource.TestCheckResourceAttr(resourceName, "skip_backfill", "false"),
+				),
+			},
+			// Reformatting the JSON definition shouldn't cause a diff
+			{
+				Config: baseConfig + `
+					resource "sneller_table" "test" {
+						region   = sneller_tenant_region.test.region
+						database = "` + acctest.DatabaseName + `"
+						table    = "` + acctest.TableName + `"
+
+						definition_json = <<-EOT
+						{
+							"partitions": [ { "field": "tenant" } ],
+							"input": [ { "format": "json", "pattern": "s3://` + acctest.Bucket1Name + `/data/*.ndjson" } ]
+						}
+						EOT
+					}`,
+				PlanOnly: true,
+			},
+			// Delete is automatically tested
+		},
+	})
+}
+
+func TestAccResourceTableInputBlocks(t *testing.T) {
+	resourceName := "sneller_table.test"
+	baseConfig := acctest.ProviderConfig + `
+		resource "sneller_tenant_region" "test" {
+			region   = "` + api.DefaultSnellerRegion + `"
+			bucket   = "` + acctest.Bucket1Name + `"
+			role_arn = "` + acctest.Role1ARN + `"
+		}`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: acctest.TestAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Combining input blocks and inputs is rejected
+			{
+				Config: baseConfig + `
+					resource "sneller_table" "test" {
+						region   = sneller_tenant_region.test.region
+						database = "` + acctest.DatabaseName + `"
+						table    = "` + acctest.TableName + `"
+
+						inputs = [{
+							pattern = "s3://` + acctest.Bucket1Name + `/data/*.ndjson"
+							format  = "json"
+						}]
+
+						input {
+							pattern = "s3://` + acctest.Bucket2Name + `/data/*.ndjson"
+							format  = "json"
+						}
+					}`,
+				ExpectError: regexp.MustCompile("Conflicting table definition"),
+			},
+			// Create and Read testing
+			{
+				Config: baseConfig + `
+					resource "sneller_table" "test" {
+						region   = sneller_tenant_region.test.region
+						database = "` + acctest.DatabaseName + `"
+						table    = "` + acctest.TableName + `"
+
+						input {
+							pattern = "s3://` + acctest.Bucket1Name + `/data/*.ndjson"
+							format  = "json"
+						}
+
+						input {
+							pattern = "s3://` + acctest.Bucket2Name + `/data/*.ndjson"
+							format  = "json.gz"
+						}
+					}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", fmt.Sprintf("%s/%s/%s/%s", acctest.SnellerTenantID, api.DefaultSnellerRegion, acctest.DatabaseName, acctest.TableName)),
+					resource.TestCheckResourceAttr(resourceName, "input.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "input.0.pattern", "s3://"+acctest.Bucket1Name+"/data/*.ndjson"),
+					resource.TestCheckResourceAttr(resourceName, "input.1.format", "json.gz"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.#", "2"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.0.pattern", "s3://"+acctest.Bucket1Name+"/data/*.ndjson"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.1.format", "json.gz"),
+				),
+			},
+			// Switching to the inputs attribute keeps the table
+			{
+				Config: baseConfig + `
+					resource "sneller_table" "test" {
+						region   = sneller_tenant_region.test.region
+						database = "` + acctest.DatabaseName + `"
+						table    = "` + acctest.TableName + `"
+
+						inputs = [{
+							pattern = "s3://` + acctest.Bucket1Name + `/data/*.ndjson"
+							format  = "json"
+						}]
+					}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "input.#", "0"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.#", "1"),
+					resource.TestCheckResourceAttr(resourceName, "inputs.0.format", "json"),
+				),
+			},
+			// Delete is automatically tested
+		},
+	})
+}
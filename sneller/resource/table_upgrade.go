@@ -0,0 +1,185 @@
+package resource
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// tableSchemaUpgrades returns the upgrades of the table schema.
+func tableSchemaUpgrades() []schemaUpgrade {
+	return []schemaUpgrade{
+		// version 1 added the input blocks
+		{priorSchema: tableSchemaV0, upgrade: upgradeTableStateV0},
+		// version 2 added the path of the JSON hints (and
+		// deprecated the field attribute)
+		{priorSchema: tableSchemaV1, upgrade: upgradeTableStateV1},
+	}
+}
+
+// The prior schemas are declared as they were released, so they
+// don't change with the current schema. Only the attributes and
+// their types are declared, because the prior schemas are only
+// used to decode the saved state.
+
+// tableSchemaV0 returns the table schema before the input blocks
+// were supported.
+func tableSchemaV0(_ context.Context) schema.Schema {
+	return schema.Schema{
+		Version:    0,
+		Attributes: tableAttributesV0(),
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlockV0(),
+		},
+	}
+}
+
+// tableSchemaV1 returns the table schema before the path of the
+// JSON hints was added.
+func tableSchemaV1(_ context.Context) schema.Schema {
+	return schema.Schema{
+		Version:    1,
+		Attributes: tableAttributesV0(),
+		Blocks: map[string]schema.Block{
+			"input": schema.ListNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: tableInputAttributesV0(),
+				},
+			},
+			"timeouts": timeoutsBlockV0(),
+		},
+	}
+}
+
+// tableAttributesV0 returns the table attributes of versions 0
+// and 1 of the schema.
+func tableAttributesV0() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"id":        schema.StringAttribute{Computed: true},
+		"tenant_id": schema.StringAttribute{Optional: true, Computed: true},
+		"region":    schema.StringAttribute{Optional: true, Computed: true},
+		"database":  schema.StringAttribute{Required: true},
+		"table":     schema.StringAttribute{Required: true},
+		"location":  schema.StringAttribute{Computed: true},
+		"inputs": schema.ListNestedAttribute{
+			Optional: true,
+			Computed: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: tableInputAttributesV0(),
+			},
+		},
+		"partitions": schema.ListNestedAttribute{
+			Optional: true,
+			Computed: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"field": schema.StringAttribute{Required: true},
+					"type":  schema.StringAttribute{Optional: true},
+					"value": schema.StringAttribute{Optional: true},
+				},
+			},
+		},
+		"retention_policy": schema.SingleNestedAttribute{
+			Optional: true,
+			Computed: true,
+			Attributes: map[string]schema.Attribute{
+				"field":     schema.StringAttribute{Required: true},
+				"valid_for": schema.StringAttribute{Required: true},
+			},
+		},
+		"beta_features":   schema.ListAttribute{Optional: true, Computed: true, ElementType: types.StringType},
+		"skip_backfill":   schema.BoolAttribute{Optional: true, Computed: true},
+		"definition_json": schema.StringAttribute{Optional: true, Computed: true},
+		"extra_json":      schema.StringAttribute{Computed: true},
+		"wait_for_index":  schema.BoolAttribute{Optional: true},
+		"has_definition":  schema.BoolAttribute{Computed: true},
+		"has_index":       schema.BoolAttribute{Computed: true},
+	}
+}
+
+// tableInputAttributesV0 returns the input attributes of versions
+// 0 and 1 of the schema.
+func tableInputAttributesV0() map[string]schema.Attribute {
+	fields := schema.ListNestedAttribute{
+		Optional: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"name":           schema.StringAttribute{Required: true},
+				"type":           schema.StringAttribute{Optional: true},
+				"default":        schema.StringAttribute{Optional: true},
+				"format":         schema.StringAttribute{Optional: true},
+				"allow_empty":    schema.BoolAttribute{Optional: true, Computed: true},
+				"no_index":       schema.BoolAttribute{Optional: true, Computed: true},
+				"true_values":    schema.ListAttribute{Optional: true, ElementType: types.StringType},
+				"false_values":   schema.ListAttribute{Optional: true, ElementType: types.StringType},
+				"missing_values": schema.ListAttribute{Optional: true, ElementType: types.StringType},
+			},
+		},
+	}
+	return map[string]schema.Attribute{
+		"pattern": schema.StringAttribute{Required: true},
+		"format":  schema.StringAttribute{Required: true},
+		"json_hints": schema.ListNestedAttribute{
+			Optional: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"field": schema.StringAttribute{Required: true},
+					"hints": schema.ListAttribute{Required: true, ElementType: types.StringType},
+				},
+			},
+		},
+		"csv_hints": schema.SingleNestedAttribute{
+			Optional: true,
+			Attributes: map[string]schema.Attribute{
+				"separator":      schema.StringAttribute{Optional: true},
+				"skip_records":   schema.Int64Attribute{Optional: true},
+				"missing_values": schema.ListAttribute{Optional: true, ElementType: types.StringType},
+				"fields":         fields,
+			},
+		},
+		"tsv_hints": schema.SingleNestedAttribute{
+			Optional: true,
+			Attributes: map[string]schema.Attribute{
+				"skip_records":   schema.Int64Attribute{Optional: true},
+				"missing_values": schema.ListAttribute{Optional: true, ElementType: types.StringType},
+				"fields":         fields,
+			},
+		},
+	}
+}
+
+// timeoutsBlockV0 returns the timeouts block of versions 0 and 1
+// of the schema.
+func timeoutsBlockV0() schema.Block {
+	return schema.SingleNestedBlock{
+		Attributes: map[string]schema.Attribute{
+			"create": schema.StringAttribute{Optional: true},
+			"read":   schema.StringAttribute{Optional: true},
+			"update": schema.StringAttribute{Optional: true},
+			"delete": schema.StringAttribute{Optional: true},
+		},
+	}
+}
+
+// upgradeTableStateV0 stores the input blocks, which are missing
+// in the state, as an empty list.
+func upgradeTableStateV0(state map[string]any) error {
+	if state["input"] == nil {
+		state["input"] = []any{}
+	}
+	return nil
+}
+
+// upgradeTableStateV1 sets the path of the JSON hints to the
+// value of the deprecated field attribute.
+func upgradeTableStateV1(state map[string]any) error {
+	for _, name := range []string{"inputs", "input"} {
+		for _, input := range stateObjects(state[name]) {
+			for _, hint := range stateObjects(input["json_hints"]) {
+				hint["path"] = hint["field"]
+			}
+		}
+	}
+	return nil
+}
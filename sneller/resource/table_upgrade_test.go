@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"context"
+	"terraform-provider-sneller/sneller/model"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+func TestUpgradeTableState(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  int64
+		rawState string
+		input    int
+	}{
+		{
+			name:    "v0",
+			version: 0,
+			rawState: `{
+				"id": "tenant/us-east-1/db/table",
+				"tenant_id": "tenant",
+				"region": "us-east-1",
+				"database": "db",
+				"table": "table",
+				"location": "s3://bucket/db/db/table/",
+				"inputs": [{
+					"pattern": "s3://bucket/*.ndjson",
+					"format": "json",
+					"json_hints": [{"field": "a.b", "hints": ["string"]}],
+					"csv_hints": null,
+					"tsv_hints": null
+				}],
+				"partitions": null,
+				"retention_policy": null,
+				"beta_features": null,
+				"skip_backfill": false,
+				"definition_json": null,
+				"extra_json": null,
+				"timeouts": null
+			}`,
+		},
+		{
+			name:    "v1",
+			version: 1,
+			rawState: `{
+				"id": "tenant/us-east-1/db/table",
+				"tenant_id": "tenant",
+				"region": "us-east-1",
+				"database": "db",
+				"table": "table",
+				"location": "s3://bucket/db/db/table/",
+				"inputs": [{
+					"pattern": "s3://bucket/*.ndjson",
+					"format": "json",
+					"json_hints": [{"field": "a.b", "hints": ["string"]}],
+					"csv_hints": null,
+					"tsv_hints": null
+				}],
+				"input": [{
+					"pattern": "s3://bucket/*.ndjson",
+					"format": "json",
+					"json_hints": [{"field": "a.b", "hints": ["string"]}],
+					"csv_hints": null,
+					"tsv_hints": null
+				}],
+				"skip_backfill": false,
+				"wait_for_index": null,
+				"has_definition": true,
+				"has_index": false
+			}`,
+			input: 1,
+		},
+	}
+
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	(&tableResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	if v := schemaResp.Schema.Version; v != 2 {
+		t.Fatalf("unexpected schema version %d", v)
+	}
+	upgraders := (&tableResource{}).UpgradeState(ctx)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: []byte(tt.rawState)}}
+			var resp resource.UpgradeStateResponse
+			upgraders[tt.version].StateUpgrader(ctx, req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics %v", resp.Diagnostics)
+			}
+
+			value, err := resp.DynamicValue.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := tfsdk.State{Schema: schemaResp.Schema, Raw: value}
+			var data tableResourceModel
+			if diags := state.Get(ctx, &data); diags.HasError() {
+				t.Fatalf("unexpected diagnostics %v", diags)
+			}
+			if data.Input == nil || len(data.Input) != tt.input {
+				t.Errorf("unexpected input blocks %v", data.Input)
+			}
+			if len(data.Inputs) != 1 || data.Inputs[0].Pattern != "s3://bucket/*.ndjson" {
+				t.Fatalf("unexpected inputs %v", data.Inputs)
+			}
+			for _, inputs := range [][]model.TableInputModel{data.Inputs, data.Input} {
+				for _, input := range inputs {
+					hint := input.JSONHints[0]
+					if hint.Path != "a.b" || hint.Field == nil || *hint.Field != "a.b" {
+						t.Errorf("unexpected JSON hint %+v", hint)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradeTableStateMismatch(t *testing.T) {
+	ctx := context.Background()
+	upgraders := (&tableResource{}).UpgradeState(ctx)
+
+	tests := []struct {
+		name     string
+		version  int64
+		rawState string
+	}{
+		// the input blocks of version 1 are a list
+		{"input-object", 1, `{"input": {"pattern": 1}}`},
+		// the input blocks were added in version 1
+		{"input-v0", 0, `{"input": []}`},
+		// the path of JSON hints was added in version 2
+		{"json-hint-path-v1", 1, `{"inputs": [{"pattern": "s3://bucket/*.ndjson", "format": "json", "json_hints": [{"field": "a", "path": "a", "hints": ["string"]}]}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: []byte(tt.rawState)}}
+			var resp resource.UpgradeStateResponse
+			upgraders[tt.version].StateUpgrader(ctx, req, &resp)
+			if !resp.Diagnostics.HasError() {
+				t.Error("expected an error for a state that doesn't match the prior schema")
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/model"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"golang.org/x/exp/slices"
+)
+
+// TableDefinitionDiagnostic is an error in a table definition. The
+// pointer is a JSON pointer (RFC 6901) that refers to the invalid
+// value in the definition document.
+type TableDefinitionDiagnostic struct {
+	Pointer string
+	Summary string
+	Detail  string
+}
+
+var (
+	// partition placeholders of input patterns (i.e. `{date}`)
+	patternPlaceholder = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	// placeholder references of partition values (i.e. `$yyyy` or `${yyyy}`)
+	valuePlaceholder = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+	// retention periods (i.e. `1y6m` or `30d`)
+	retentionPeriod = regexp.MustCompile(`^([0-9]+y)?([0-9]+m)?([0-9]+d)?$`)
+)
+
+// Hints that can be set for CSV and TSV inputs.
+var (
+	csvHintFields = []string{"separator", "skip_records", "missing_values", "fields"}
+	tsvHintFields = []string{"skip_records", "missing_values", "fields"}
+)
+
+// ValidateTableDefinition validates a table definition (i.e. the
+// contents of a `definition.json` file) without the Sneller API.
+// It checks the input formats, whether the hints are valid for the
+// format, whether the partitions match the placeholders of all
+// input patterns and the retention policy.
+func ValidateTableDefinition(definition []byte) []TableDefinitionDiagnostic {
+	var v tableDefinitionValidator
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(definition, &fields); err != nil {
+		v.add("", "Invalid table definition", fmt.Sprintf("The table definition isn't a JSON object: %v", err))
+		return v.diags
+	}
+
+	patterns := v.validateInputs(fields["input"])
+	v.validatePartitions(fields["partitions"], patterns)
+	v.validateRetentionPolicy(fields["retention_policy"])
+	if len(v.diags) > 0 {
+		return v.diags
+	}
+
+	// the definition should be readable by the table resource
+	var data tableResourceModel
+	if err := data.setDefinition(definition); err != nil {
+		v.add("", "Invalid table definition", fmt.Sprintf("Unable to decode the table definition: %v", err))
+	}
+	return v.diags
+}
+
+// ValidateTableConfig validates the definition of a sneller_table
+// resource with the given (fully known) configuration. The
+// definition that the resource writes is validated, so the
+// pointers refer to that document. The configuration itself
+// (i.e. hints that aren't valid for the format) is validated by
+// the schema of the resource, so it should be validated using the
+// provider first.
+func ValidateTableConfig(ctx context.Context, config tftypes.Value) ([]TableDefinitionDiagnostic, error) {
+	if !config.IsFullyKnown() {
+		return nil, errors.New("the configuration has unknown values")
+	}
+	config, err := tftypes.Transform(config, setJSONHintPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemaResp resource.SchemaResponse
+	(&tableResource{}).Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	var data tableResourceModel
+	diags := tfsdk.Config{Schema: schemaResp.Schema, Raw: config}.Get(ctx, &data)
+	if diags.HasError() {
+		d := diags.Errors()[0]
+		return nil, fmt.Errorf("%s: %s", d.Summary(), d.Detail())
+	}
+	if !data.DefinitionJSON.IsNull() {
+		return ValidateTableDefinition([]byte(data.DefinitionJSON.ValueString())), nil
+	}
+
+	if len(data.Input) > 0 {
+		data.Inputs = data.Input
+	}
+	definition, err := data.canonicalDefinition()
+	if err != nil {
+		return nil, err
+	}
+	return ValidateTableDefinition(definition), nil
+}
+
+// setJSONHintPath sets the path of JSON hints that only set the
+// deprecated field, like the plan modifier of the path does.
+func setJSONHintPath(_ *tftypes.AttributePath, value tftypes.Value) (tftypes.Value, error) {
+	if !value.Type().Is(tftypes.Object{}) || value.IsNull() {
+		return value, nil
+	}
+	var attributes map[string]tftypes.Value
+	if err := value.As(&attributes); err != nil {
+		return value, err
+	}
+	pathValue, hasPath := attributes["path"]
+	field, hasField := attributes["field"]
+	if !hasPath || !hasField || !pathValue.IsNull() {
+		return value, nil
+	}
+	attributes["path"] = field
+	return tftypes.NewValue(value.Type(), attributes), nil
+}
+
+// tableDefinitionValidator collects the errors of a definition.
+type tableDefinitionValidator struct {
+	diags []TableDefinitionDiagnostic
+}
+
+func (v *tableDefinitionValidator) add(pointer, summary, detail string) {
+	v.diags = append(v.diags, TableDefinitionDiagnostic{Pointer: pointer, Summary: summary, Detail: detail})
+}
+
+// validateInputs validates the inputs and returns their patterns.
+func (v *tableDefinitionValidator) validateInputs(data json.RawMessage) []string {
+	if len(data) == 0 || string(data) == "null" {
+		v.add("/input", "Missing inputs", "The table definition should have at least one input.")
+		return nil
+	}
+	var inputs []json.RawMessage
+	if err := json.Unmarshal(data, &inputs); err != nil {
+		v.add("/input", "Invalid inputs", fmt.Sprintf("The inputs should be an array: %v", err))
+		return nil
+	}
+	if len(inputs) == 0 {
+		v.add("/input", "Missing inputs", "The table definition should have at least one input.")
+		return nil
+	}
+
+	var patterns []string
+	for i, data := range inputs {
+		p := jsonPointer("input", strconv.Itoa(i))
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			v.add(p, "Invalid input", fmt.Sprintf("The input should be an object: %v", err))
+			continue
+		}
+		n := len(v.diags)
+
+		var pattern string
+		if err := json.Unmarshal(fields["pattern"], &pattern); err != nil || pattern == "" {
+			v.add(p+"/pattern", "Missing pattern", "The input should have a pattern.")
+		} else {
+			patterns = append(patterns, pattern)
+		}
+
+		format := "json"
+		if data := fields["format"]; len(data) > 0 && string(data) != "null" {
+			if err := json.Unmarshal(data, &format); err != nil {
+				v.add(p+"/format", "Invalid format", fmt.Sprintf("The format should be a string: %v", err))
+				continue
+			}
+			if !slices.Contains(api.Formats, model.CanonicalFormat(format)) {
+				v.add(p+"/format", "Invalid format", fmt.Sprintf("The format %q isn't supported (expected one of '%s').", format, strings.Join(api.Formats, "', '")))
+				continue
+			}
+			format = model.CanonicalFormat(format)
+		}
+		if hints := fields["hints"]; len(hints) > 0 && string(hints) != "null" {
+			v.validateHints(p+"/hints", format, hints)
+		}
+
+		// the input should be readable by the table resource
+		var input model.TableInputModel
+		if err := json.Unmarshal(data, &input); err != nil && len(v.diags) == n {
+			v.add(p, "Invalid input", fmt.Sprintf("Unable to decode the input: %v", err))
+		}
+	}
+	return patterns
+}
+
+// validateHints validates the hints of an input with the given
+// format, which determines the type of the hints.
+func (v *tableDefinitionValidator) validateHints(p, format string, data json.RawMessage) {
+	switch {
+	case slices.Contains(filterFormats("json"), format):
+		var hints model.TableInputJSONHintsModel
+		if err := json.Unmarshal(data, &hints); err != nil {
+			v.add(p, "Invalid hints", fmt.Sprintf("The hints aren't valid for format %q: %v", format, err))
+		}
+	case slices.Contains(filterFormats("csv"), format):
+		var hints model.TableInputCSVHintModel
+		if v.validateXSVHints(p, format, data, csvHintFields, &hints) && hints.Separator != nil && len([]rune(*hints.Separator)) != 1 {
+			v.add(p+"/separator", "Invalid separator", fmt.Sprintf("The separator %q should be a single character.", *hints.Separator))
+		}
+	case slices.Contains(filterFormats("tsv"), format):
+		var hints model.TableInputTSVHintModel
+		v.validateXSVHints(p, format, data, tsvHintFields, &hints)
+	default:
+		v.add(p, "Hints not valid for format", fmt.Sprintf("Hints can't be set for format %q.", format))
+	}
+}
+
+// validateXSVHints validates CSV or TSV hints and decodes them. It
+// returns false when the hints are invalid.
+func (v *tableDefinitionValidator) validateXSVHints(p, format string, data json.RawMessage, names []string, hints any) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		v.add(p, "Invalid hints", fmt.Sprintf("The hints of format %q should be an object: %v", format, err))
+		return false
+	}
+	valid := true
+	for _, name := range sortedKeys(fields) {
+		if !slices.Contains(names, name) {
+			v.add(p+"/"+escapeJSONPointer(name), "Hint not valid for format", fmt.Sprintf("The hint %q can't be set for format %q.", name, format))
+			valid = false
+		}
+	}
+	if err := json.Unmarshal(data, hints); err != nil {
+		v.add(p, "Invalid hints", fmt.Sprintf("The hints aren't valid for format %q: %v", format, err))
+		return false
+	}
+	return valid
+}
+
+// validatePartitions validates that the placeholders of the
+// partitions exist in all input patterns.
+func (v *tableDefinitionValidator) validatePartitions(data json.RawMessage, patterns []string) {
+	if len(data) == 0 || string(data) == "null" {
+		return
+	}
+	var partitions []json.RawMessage
+	if err := json.Unmarshal(data, &partitions); err != nil {
+		v.add("/partitions", "Invalid partitions", fmt.Sprintf("The partitions should be an array: %v", err))
+		return
+	}
+
+	fields := make(map[string]bool, len(partitions))
+	for i, data := range partitions {
+		p := jsonPointer("partitions", strconv.Itoa(i))
+		var partition model.TablePartitionModel
+		if err := json.Unmarshal(data, &partition); err != nil {
+			v.add(p, "Invalid partition", fmt.Sprintf("Unable to decode the partition: %v", err))
+			continue
+		}
+		if partition.Field == "" {
+			v.add(p+"/field", "Missing partition field", "The partition should have a field.")
+			continue
+		}
+		if fields[partition.Field] {
+			v.add(p+"/field", "Duplicate partition field", fmt.Sprintf("The partition field %q is defined more than once.", partition.Field))
+			continue
+		}
+		fields[partition.Field] = true
+
+		// without a value, the field is the placeholder
+		placeholders, placeholderPointer := []string{partition.Field}, p+"/field"
+		if partition.Value != nil && *partition.Value != "" {
+			placeholders, placeholderPointer = nil, p+"/value"
+			for _, match := range valuePlaceholder.FindAllStringSubmatch(*partition.Value, -1) {
+				placeholders = append(placeholders, match[1]+match[2])
+			}
+		}
+		for _, pattern := range patterns {
+			var names []string
+			for _, match := range patternPlaceholder.FindAllStringSubmatch(pattern, -1) {
+				names = append(names, match[1])
+			}
+			for _, placeholder := range placeholders {
+				if !slices.Contains(names, placeholder) {
+					v.add(placeholderPointer, "Missing placeholder", fmt.Sprintf("The input pattern %q has no {%s} placeholder for partition %q.", pattern, placeholder, partition.Field))
+				}
+			}
+		}
+	}
+}
+
+// validateRetentionPolicy validates the retention period.
+func (v *tableDefinitionValidator) validateRetentionPolicy(data json.RawMessage) {
+	if len(data) == 0 || string(data) == "null" {
+		return
+	}
+	var policy model.TableRetentionModel
+	if err := json.Unmarshal(data, &policy); err != nil {
+		v.add("/retention_policy", "Invalid retention policy", fmt.Sprintf("Unable to decode the retention policy: %v", err))
+		return
+	}
+	if policy.Field == "" {
+		v.add("/retention_policy/field", "Missing retention field", "The retention policy should have a field.")
+	}
+	if policy.ValidFor == "" || !retentionPeriod.MatchString(policy.ValidFor) {
+		v.add("/retention_policy/valid_for", "Invalid retention period", fmt.Sprintf("The retention period %q should be '<n>y<n>m<n>d' where '<n>' is a number and any component can be omitted.", policy.ValidFor))
+	}
+}
+
+// jsonPointer returns the JSON pointer of the given reference tokens.
+func jsonPointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(escapeJSONPointer(token))
+	}
+	return sb.String()
+}
+
+func escapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,196 @@
+package resource
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestValidateTableDefinition(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		pointers   []string
+	}{
+		{
+			name: "valid",
+			definition: `{
+				"input": [
+					{"pattern": "s3://source/{region}/{yyyy}/{mm}/{dd}/*.ndjson", "format": ".json", "hints": {"ts": "datetime"}},
+					{"pattern": "s3://source/{region}/{yyyy}/{mm}/{dd}/*.csv", "format": "csv", "hints": {"separator": ";", "fields": [{"name": "ts", "type": "datetime"}]}}
+				],
+				"partitions": [{"field": "region"}, {"field": "date", "type": "date", "value": "$yyyy-$mm-${dd}"}],
+				"retention_policy": {"field": "ts", "valid_for": "1y6m"}
+			}`,
+		},
+		{
+			name:       "invalid-json",
+			definition: `{"input": [`,
+			pointers:   []string{""},
+		},
+		{
+			name:       "missing-input",
+			definition: `{"partitions": []}`,
+			pointers:   []string{"/input"},
+		},
+		{
+			name:       "missing-pattern",
+			definition: `{"input": [{"format": "json"}]}`,
+			pointers:   []string{"/input/0/pattern"},
+		},
+		{
+			name:       "invalid-format",
+			definition: `{"input": [{"pattern": "s3://source/*.parquet", "format": "parquet"}]}`,
+			pointers:   []string{"/input/0/format"},
+		},
+		{
+			name:       "invalid-json-hints",
+			definition: `{"input": [{"pattern": "s3://source/*.ndjson", "hints": {"ts": 1}}]}`,
+			pointers:   []string{"/input/0/hints"},
+		},
+		{
+			name:       "hints-for-cloudtrail",
+			definition: `{"input": [{"pattern": "s3://source/*.json.gz", "format": "cloudtrail.json.gz", "hints": {"ts": "datetime"}}]}`,
+			pointers:   []string{"/input/0/hints"},
+		},
+		{
+			name:       "separator-for-tsv",
+			definition: `{"input": [{"pattern": "s3://source/*.tsv", "format": "tsv", "hints": {"separator": ";", "skip_records": 1}}]}`,
+			pointers:   []string{"/input/0/hints/separator"},
+		},
+		{
+			name:       "invalid-csv-separator",
+			definition: `{"input": [{"pattern": "s3://source/*.csv", "format": "csv", "hints": {"separator": ";;"}}]}`,
+			pointers:   []string{"/input/0/hints/separator"},
+		},
+		{
+			name:       "invalid-csv-hints",
+			definition: `{"input": [{"pattern": "s3://source/*.csv", "format": "csv", "hints": [{"path": "ts", "hints": "datetime"}]}]}`,
+			pointers:   []string{"/input/0/hints"},
+		},
+		{
+			name: "missing-partition-placeholder",
+			definition: `{
+				"input": [{"pattern": "s3://source/{region}/*.ndjson"}, {"pattern": "s3://other/*.ndjson"}],
+				"partitions": [{"field": "region"}]
+			}`,
+			pointers: []string{"/partitions/0/field"},
+		},
+		{
+			name: "missing-value-placeholder",
+			definition: `{
+				"input": [{"pattern": "s3://source/{yyyy}/{mm}/*.ndjson"}],
+				"partitions": [{"field": "date", "value": "$yyyy-$mm-$dd"}]
+			}`,
+			pointers: []string{"/partitions/0/value"},
+		},
+		{
+			name: "duplicate-partition",
+			definition: `{
+				"input": [{"pattern": "s3://source/{region}/*.ndjson"}],
+				"partitions": [{"field": "region"}, {"field": "region"}, {"type": "string"}]
+			}`,
+			pointers: []string{"/partitions/1/field", "/partitions/2/field"},
+		},
+		{
+			name: "invalid-retention",
+			definition: `{
+				"input": [{"pattern": "s3://source/*.ndjson"}],
+				"retention_policy": {"field": "", "valid_for": "30 days"}
+			}`,
+			pointers: []string{"/retention_policy/field", "/retention_policy/valid_for"},
+		},
+		{
+			name: "invalid-skip-backfill",
+			definition: `{
+				"input": [{"pattern": "s3://source/*.ndjson"}],
+				"skip_backfill": "yes"
+			}`,
+			pointers: []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := ValidateTableDefinition([]byte(tt.definition))
+			var pointers []string
+			for _, d := range diags {
+				pointers = append(pointers, d.Pointer)
+			}
+			if strings.Join(pointers, ",") != strings.Join(tt.pointers, ",") {
+				t.Errorf("got errors %+v, expected pointers %q", diags, tt.pointers)
+			}
+		})
+	}
+}
+
+func TestValidateTableConfig(t *testing.T) {
+	ctx := context.Background()
+	config := testTableConfig(t, nil)
+	objectType := config.Raw.Type().(tftypes.Object)
+	inputsType := objectType.AttributeTypes["inputs"].(tftypes.List)
+	inputType := inputsType.ElementType.(tftypes.Object)
+	jsonHintsType := inputType.AttributeTypes["json_hints"].(tftypes.List)
+	jsonHintType := jsonHintsType.ElementType.(tftypes.Object)
+
+	nullAttrs := func(t tftypes.Object) map[string]tftypes.Value {
+		attrs := make(map[string]tftypes.Value, len(t.AttributeTypes))
+		for name, attrType := range t.AttributeTypes {
+			attrs[name] = tftypes.NewValue(attrType, nil)
+		}
+		return attrs
+	}
+
+	// the JSON hints use the deprecated field instead of the path
+	hintAttrs := nullAttrs(jsonHintType)
+	hintAttrs["field"] = tftypes.NewValue(tftypes.String, "ts")
+	hintAttrs["hints"] = tftypes.NewValue(jsonHintType.AttributeTypes["hints"], []tftypes.Value{tftypes.NewValue(tftypes.String, "datetime")})
+	jsonHints := tftypes.NewValue(jsonHintsType, []tftypes.Value{tftypes.NewValue(jsonHintType, hintAttrs)})
+
+	newInputs := func(format string) tftypes.Value {
+		attrs := nullAttrs(inputType)
+		attrs["pattern"] = tftypes.NewValue(tftypes.String, "s3://source/{region}/*.ndjson")
+		attrs["format"] = tftypes.NewValue(tftypes.String, format)
+		attrs["json_hints"] = jsonHints
+		return tftypes.NewValue(inputsType, []tftypes.Value{tftypes.NewValue(inputType, attrs)})
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]tftypes.Value
+		pointers []string
+	}{
+		{"inputs", map[string]tftypes.Value{"inputs": newInputs("json")}, nil},
+		{"input-blocks", map[string]tftypes.Value{"input": newInputs("json.gz")}, nil},
+		{"definition_json", map[string]tftypes.Value{"definition_json": tftypes.NewValue(tftypes.String, `{"input": [{"pattern": "s3://source/*.csv", "format": "xml"}]}`)}, []string{"/input/0/format"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]tftypes.Value{
+				"database": tftypes.NewValue(tftypes.String, "db"),
+				"table":    tftypes.NewValue(tftypes.String, "table"),
+			}
+			for name, value := range tt.values {
+				values[name] = value
+			}
+			diags, err := ValidateTableConfig(ctx, testTableConfig(t, values).Raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var pointers []string
+			for _, d := range diags {
+				pointers = append(pointers, d.Pointer)
+			}
+			if strings.Join(pointers, ",") != strings.Join(tt.pointers, ",") {
+				t.Errorf("got errors %+v, expected pointers %q", diags, tt.pointers)
+			}
+		})
+	}
+
+	unknown := testTableConfig(t, map[string]tftypes.Value{"inputs": tftypes.NewValue(inputsType, tftypes.UnknownValue)})
+	if _, err := ValidateTableConfig(ctx, unknown.Raw); err == nil {
+		t.Error("expected error for unknown configuration")
+	}
+}
@@ -2,17 +2,20 @@ package resource
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 
 	"github.com/aws/aws-sdk-go-v2/aws/arn"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 func NewTenantRegionResource() resource.Resource {
@@ -31,15 +34,17 @@ type tenantRegionResource struct {
 }
 
 type tenantRegionResourceModel struct {
-	ID                    types.String `tfsdk:"id"`
-	Region                types.String `tfsdk:"region"`
-	Bucket                types.String `tfsdk:"bucket"`
-	Prefix                types.String `tfsdk:"prefix"`
-	RoleARN               types.String `tfsdk:"role_arn"`
-	ExternalID            types.String `tfsdk:"external_id"`
-	MaxScanBytes          types.Int64  `tfsdk:"max_scan_bytes"`
-	EffectiveMaxScanBytes types.Int64  `tfsdk:"effective_max_scan_bytes"`
-	SqsARN                types.String `tfsdk:"sqs_arn"`
+	ID                    types.String   `tfsdk:"id"`
+	TenantID              types.String   `tfsdk:"tenant_id"`
+	Region                types.String   `tfsdk:"region"`
+	Bucket                types.String   `tfsdk:"bucket"`
+	Prefix                types.String   `tfsdk:"prefix"`
+	RoleARN               types.String   `tfsdk:"role_arn"`
+	ExternalID            types.String   `tfsdk:"external_id"`
+	MaxScanBytes          types.Int64    `tfsdk:"max_scan_bytes"`
+	EffectiveMaxScanBytes types.Int64    `tfsdk:"effective_max_scan_bytes"`
+	SqsARN                types.String   `tfsdk:"sqs_arn"`
+	Timeouts              timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *tenantRegionResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -55,6 +60,7 @@ func (r *tenantRegionResource) Schema(ctx context.Context, req resource.SchemaRe
 				Computed:      true,
 				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
+			"tenant_id": tenantIDAttribute(),
 			"region": schema.StringAttribute{
 				Description: "Region from which to fetch the tenant configuration. When not set, then it default's to the tenant's home region.",
 				Optional:    true,
@@ -96,6 +102,9 @@ func (r *tenantRegionResource) Schema(ctx context.Context, req resource.SchemaRe
 				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
 	}
 }
 
@@ -107,6 +116,11 @@ func (r *tenantRegionResource) Configure(ctx context.Context, req resource.Confi
 }
 
 func (r *tenantRegionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		// the prior state is kept until the provider is
+		// configured (see providerdata.CheckClient)
+		return
+	}
 
 	var data tenantRegionResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -114,34 +128,45 @@ func (r *tenantRegionResource) Read(ctx context.Context, req resource.ReadReques
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "read", data.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	region := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			tflog.Debug(ctx, "Tenant region not found, removing from state", map[string]any{"region": region})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
 			fmt.Sprintf("Unable to get tenant info in region %s: %v", region, err.Error()),
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
-	tenantRegionInfo := tenantInfo.Regions[region]
+	tenantRegionInfo, ok := tenantInfo.Regions[region]
+	if !ok || tenantRegionInfo.Bucket == "" {
+		// the region has been reset (or never configured)
+		tflog.Debug(ctx, "Tenant region not configured, removing from state", map[string]any{"region": region})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 
 	sqsARN := tenantRegionInfo.SqsArn
 	if tenantRegionInfo.SqsArn == "" {
@@ -155,6 +180,7 @@ func (r *tenantRegionResource) Read(ctx context.Context, req resource.ReadReques
 		}
 	}
 
+	data.TenantID = types.StringValue(tenantInfo.TenantID)
 	data.Region = types.StringValue(region)
 	data.Bucket = types.StringValue(strings.TrimPrefix(tenantRegionInfo.Bucket, "s3://"))
 	data.Prefix = types.StringValue(api.DefaultDbPrefix)
@@ -170,6 +196,10 @@ func (r *tenantRegionResource) Read(ctx context.Context, req resource.ReadReques
 }
 
 func (r *tenantRegionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data tenantRegionResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -177,9 +207,17 @@ func (r *tenantRegionResource) Create(ctx context.Context, req resource.CreateRe
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, "create", data.Timeouts.Create, defaultCreateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
 	region := data.Region.ValueString()
 	if region == "" {
-		tenantInfo, err := r.client.Tenant(ctx, "")
+		tenantInfo, err := client.Tenant(ctx, "")
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Cannot get tenant info",
@@ -192,7 +230,7 @@ func (r *tenantRegionResource) Create(ctx context.Context, req resource.CreateRe
 
 	bucket := data.Bucket.ValueString()
 	roleARN := data.RoleARN.ValueString()
-	err := r.client.SetBucket(ctx, region, "s3://"+bucket, roleARN)
+	err := client.SetBucket(ctx, region, "s3://"+bucket, roleARN)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot set tenant region bucket",
@@ -202,7 +240,7 @@ func (r *tenantRegionResource) Create(ctx context.Context, req resource.CreateRe
 	}
 
 	if !data.MaxScanBytes.IsNull() && !data.MaxScanBytes.IsUnknown() {
-		_, err = r.client.SetMaxScanBytes(ctx, region, ptr(uint64(data.MaxScanBytes.ValueInt64())))
+		_, err = client.SetMaxScanBytes(ctx, region, ptr(uint64(data.MaxScanBytes.ValueInt64())))
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Cannot set max-scan-bytes value",
@@ -213,7 +251,7 @@ func (r *tenantRegionResource) Create(ctx context.Context, req resource.CreateRe
 	}
 
 	// refresh tenant information
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info (after set-bucket)",
@@ -236,6 +274,7 @@ func (r *tenantRegionResource) Create(ctx context.Context, req resource.CreateRe
 	}
 
 	data.ID = types.StringValue(fmt.Sprintf("%s/%s", tenantInfo.TenantID, region))
+	data.TenantID = types.StringValue(tenantInfo.TenantID)
 	data.Region = types.StringValue(region)
 	data.Prefix = types.StringValue(api.DefaultDbPrefix)
 	data.ExternalID = types.StringValue(tenantRegionInfo.RegionExternalID)
@@ -249,6 +288,10 @@ func (r *tenantRegionResource) Create(ctx context.Context, req resource.CreateRe
 }
 
 func (r *tenantRegionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data tenantRegionResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -256,18 +299,22 @@ func (r *tenantRegionResource) Update(ctx context.Context, req resource.UpdateRe
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "update", data.Timeouts.Update, defaultUpdateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	region := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -275,11 +322,7 @@ func (r *tenantRegionResource) Update(ctx context.Context, req resource.UpdateRe
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
@@ -287,7 +330,7 @@ func (r *tenantRegionResource) Update(ctx context.Context, req resource.UpdateRe
 
 	bucket := data.Bucket.ValueString()
 	roleARN := data.RoleARN.ValueString()
-	err = r.client.SetBucket(ctx, region, "s3://"+bucket, roleARN)
+	err = client.SetBucket(ctx, region, "s3://"+bucket, roleARN)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot set tenant region configuration",
@@ -305,7 +348,7 @@ func (r *tenantRegionResource) Update(ctx context.Context, req resource.UpdateRe
 	if (maxScanBytes == nil && currentMaxScanBytes != nil) ||
 		(maxScanBytes != nil && currentMaxScanBytes == nil) ||
 		(maxScanBytes != nil && currentMaxScanBytes != nil && *maxScanBytes != *currentMaxScanBytes) {
-		_, err = r.client.SetMaxScanBytes(ctx, region, maxScanBytes)
+		_, err = client.SetMaxScanBytes(ctx, region, maxScanBytes)
 		if err != nil {
 			resp.Diagnostics.AddError(
 				"Cannot set max-scan-bytes value",
@@ -316,7 +359,7 @@ func (r *tenantRegionResource) Update(ctx context.Context, req resource.UpdateRe
 	}
 
 	// refresh tenant information
-	tenantInfo, err = r.client.Tenant(ctx, region)
+	tenantInfo, err = client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info (after set-bucket)",
@@ -350,6 +393,10 @@ func (r *tenantRegionResource) Update(ctx context.Context, req resource.UpdateRe
 }
 
 func (r *tenantRegionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data tenantRegionResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -357,18 +404,22 @@ func (r *tenantRegionResource) Delete(ctx context.Context, req resource.DeleteRe
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "delete", data.Timeouts.Delete, defaultDeleteTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	region := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, region)
+	tenantInfo, err := client.Tenant(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -376,15 +427,11 @@ func (r *tenantRegionResource) Delete(ctx context.Context, req resource.DeleteRe
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
-	err = r.client.ResetBucket(ctx, region)
+	err = client.ResetBucket(ctx, region)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot delete tenant region configuration",
@@ -395,6 +442,5 @@ func (r *tenantRegionResource) Delete(ctx context.Context, req resource.DeleteRe
 }
 
 func (r *tenantRegionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importState(ctx, r.client, 2, resolveRegionID, req, resp)
 }
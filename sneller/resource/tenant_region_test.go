@@ -42,6 +42,13 @@ func TestAccResourceTenantRegion(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			// Import testing by region
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     api.DefaultSnellerRegion,
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: acctest.ProviderConfig + `
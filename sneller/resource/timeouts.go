@@ -0,0 +1,53 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+)
+
+// Default timeouts of the resource operations. They can be
+// overridden using the `timeouts` block of the resource.
+const (
+	defaultCreateTimeout = 10 * time.Minute
+	defaultReadTimeout   = 5 * time.Minute
+	defaultUpdateTimeout = 10 * time.Minute
+	defaultDeleteTimeout = 10 * time.Minute
+)
+
+// timeoutsBlock returns the `timeouts` block that is supported
+// by all resources.
+func timeoutsBlock(ctx context.Context) schema.Block {
+	return timeouts.Block(ctx, timeouts.Opts{
+		Create: true,
+		Read:   true,
+		Update: true,
+		Delete: true,
+	})
+}
+
+// withTimeout bounds the context of the operation by the timeout
+// that is obtained from the `timeouts` block. The returned cancel
+// function should be deferred. It releases the context and adds
+// an error diagnostic when the operation failed, because the
+// timeout expired.
+func withTimeout(ctx context.Context, operation string, timeout func(context.Context, time.Duration) (time.Duration, diag.Diagnostics), defaultTimeout time.Duration, diags *diag.Diagnostics) (context.Context, context.CancelFunc) {
+	d, timeoutDiags := timeout(ctx, defaultTimeout)
+	diags.Append(timeoutDiags...)
+
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return ctx, func() {
+		if diags.HasError() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			diags.AddError(
+				"Operation timed out",
+				fmt.Sprintf("The %s operation didn't complete within %s. The timeout can be increased using the `timeouts` block.", operation, d),
+			)
+		}
+		cancel()
+	}
+}
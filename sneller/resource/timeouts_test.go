@@ -0,0 +1,31 @@
+package resource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestWithTimeout(t *testing.T) {
+	timeout := func(context.Context, time.Duration) (time.Duration, diag.Diagnostics) {
+		return time.Millisecond, nil
+	}
+
+	var diags diag.Diagnostics
+	ctx, cancel := withTimeout(context.Background(), "create", timeout, defaultCreateTimeout, &diags)
+	<-ctx.Done()
+	diags.AddError("Cannot create table", ctx.Err().Error())
+	cancel()
+	if len(diags) != 2 || diags[1].Summary() != "Operation timed out" {
+		t.Errorf("expected timeout diagnostic, got %v", diags)
+	}
+
+	diags = nil
+	_, cancel = withTimeout(context.Background(), "read", timeout, defaultReadTimeout, &diags)
+	cancel()
+	if diags.HasError() {
+		t.Errorf("unexpected diagnostics %v", diags)
+	}
+}
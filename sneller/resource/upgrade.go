@@ -0,0 +1,113 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// schemaUpgrade describes a change of a resource schema. Each
+// upgrade increments the schema version, so the schema version
+// of a resource is the number of its upgrades.
+//
+// Terraform requests a single upgrade from the version of the
+// saved state to the current version, so the upgrades are
+// chained. The state is upgraded in its JSON representation,
+// which prevents that an upgrade depends on later schemas.
+type schemaUpgrade struct {
+	// priorSchema returns the schema before the upgrade. It is
+	// used to verify that the saved state matches the version.
+	priorSchema func(ctx context.Context) schema.Schema
+	// upgrade modifies the state (decoded as JSON) of the prior
+	// version, so it matches the next version.
+	upgrade func(state map[string]any) error
+}
+
+// schemaVersion returns the current schema version.
+func schemaVersion(upgrades []schemaUpgrade) int64 {
+	return int64(len(upgrades))
+}
+
+// stateUpgraders returns the state upgraders for all prior
+// schema versions.
+func stateUpgraders(upgrades []schemaUpgrade) map[int64]resource.StateUpgrader {
+	upgraders := make(map[int64]resource.StateUpgrader, len(upgrades))
+	for version := range upgrades {
+		upgraders[int64(version)] = resource.StateUpgrader{
+			StateUpgrader: upgradeState(upgrades, version),
+		}
+	}
+	return upgraders
+}
+
+// upgradeState returns the state upgrader that applies all upgrades
+// starting at the given version.
+func upgradeState(upgrades []schemaUpgrade, version int) func(context.Context, resource.UpgradeStateRequest, *resource.UpgradeStateResponse) {
+	return func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+		if req.RawState == nil || req.RawState.JSON == nil {
+			resp.Diagnostics.AddError(
+				"Unable to Upgrade Resource State",
+				fmt.Sprintf("The saved state of version %d can't be decoded. It should be refreshed with an older provider version first.", version),
+			)
+			return
+		}
+
+		priorType := upgrades[version].priorSchema(ctx).Type().TerraformType(ctx)
+		_, err := req.RawState.Unmarshal(priorType)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Upgrade Resource State",
+				fmt.Sprintf("The saved state doesn't match the schema of version %d: %v", version, err.Error()),
+			)
+			return
+		}
+
+		var state map[string]any
+		d := json.NewDecoder(bytes.NewReader(req.RawState.JSON))
+		d.UseNumber()
+		if err := d.Decode(&state); err != nil || state == nil {
+			resp.Diagnostics.AddError(
+				"Unable to Upgrade Resource State",
+				fmt.Sprintf("The saved state of version %d can't be decoded: %v", version, err),
+			)
+			return
+		}
+		for v := version; v < len(upgrades); v++ {
+			if err := upgrades[v].upgrade(state); err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to Upgrade Resource State",
+					fmt.Sprintf("Unable to upgrade the saved state from version %d to %d: %v", v, v+1, err.Error()),
+				)
+				return
+			}
+		}
+
+		data, err := json.Marshal(state)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to Upgrade Resource State",
+				fmt.Sprintf("Unable to encode the upgraded state: %v", err.Error()),
+			)
+			return
+		}
+		resp.DynamicValue = &tfprotov6.DynamicValue{JSON: data}
+	}
+}
+
+// stateObjects returns the objects of a nested list attribute
+// of the state. Objects that are null are skipped.
+func stateObjects(value any) []map[string]any {
+	list, _ := value.([]any)
+	objects := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if object, ok := item.(map[string]any); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
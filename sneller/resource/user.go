@@ -2,16 +2,18 @@ package resource
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 	"terraform-provider-sneller/sneller/api"
+	"terraform-provider-sneller/sneller/internal/providerdata"
 
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"golang.org/x/exp/slices"
 )
 
@@ -31,15 +33,17 @@ type userResource struct {
 }
 
 type userResourceModel struct {
-	ID          types.String `tfsdk:"id"`
-	UserID      types.String `tfsdk:"user_id"`
-	Email       types.String `tfsdk:"email"`
-	IsEnabled   types.Bool   `tfsdk:"is_enabled"`
-	IsAdmin     types.Bool   `tfsdk:"is_admin"`
-	IsFederated types.Bool   `tfsdk:"is_federated"`
-	Locale      types.String `tfsdk:"locale"`
-	GivenName   types.String `tfsdk:"given_name"`
-	FamilyName  types.String `tfsdk:"family_name"`
+	ID          types.String   `tfsdk:"id"`
+	TenantID    types.String   `tfsdk:"tenant_id"`
+	UserID      types.String   `tfsdk:"user_id"`
+	Email       types.String   `tfsdk:"email"`
+	IsEnabled   types.Bool     `tfsdk:"is_enabled"`
+	IsAdmin     types.Bool     `tfsdk:"is_admin"`
+	IsFederated types.Bool     `tfsdk:"is_federated"`
+	Locale      types.String   `tfsdk:"locale"`
+	GivenName   types.String   `tfsdk:"given_name"`
+	FamilyName  types.String   `tfsdk:"family_name"`
+	Timeouts    timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (r *userResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -55,6 +59,7 @@ func (r *userResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				Computed:      true,
 				PlanModifiers: []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
 			},
+			"tenant_id": tenantIDAttribute(),
 			"user_id": schema.StringAttribute{
 				Description: "User identifier.",
 				Computed:    true,
@@ -98,6 +103,9 @@ func (r *userResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				PlanModifiers: []planmodifier.String{StringDefaultValue("")},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(ctx),
+		},
 	}
 }
 
@@ -109,6 +117,11 @@ func (r *userResource) Configure(ctx context.Context, req resource.ConfigureRequ
 }
 
 func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	if r.client == nil {
+		// the prior state is kept until the provider is
+		// configured (see providerdata.CheckClient)
+		return
+	}
 
 	var data userResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -116,18 +129,22 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "read", data.Timeouts.Read, defaultReadTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	userID := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, "")
+	tenantInfo, err := client.Tenant(ctx, "")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -136,23 +153,25 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	user, err := r.client.User(ctx, userID)
+	user, err := client.User(ctx, userID)
 	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			tflog.Debug(ctx, "User not found, removing from state", map[string]any{"user_id": userID})
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Cannot get user",
 			fmt.Sprintf("Unable to get user %q: %v", userID, err.Error()),
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
 	data.ID = types.StringValue(fmt.Sprintf("%s/%s", tenantInfo.TenantID, user.UserID))
+	data.TenantID = types.StringValue(tenantInfo.TenantID)
 	data.UserID = types.StringValue(user.UserID)
 	data.Email = types.StringValue(user.Email)
 	data.IsEnabled = types.BoolValue(user.IsEnabled)
@@ -167,6 +186,10 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 }
 
 func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data userResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -174,6 +197,14 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
+	ctx, cancel := withTimeout(ctx, "create", data.Timeouts.Create, defaultCreateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
 	email := data.Email.ValueString()
 
 	isAdmin := false
@@ -191,7 +222,7 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 		familyName = ptr(data.FamilyName.ValueString())
 	}
 
-	tenantInfo, err := r.client.Tenant(ctx, "")
+	tenantInfo, err := client.Tenant(ctx, "")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -200,7 +231,7 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	userID, err := r.client.CreateUser(ctx, email, isAdmin, locale, givenName, familyName)
+	userID, err := client.CreateUser(ctx, email, isAdmin, locale, givenName, familyName)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot create user",
@@ -220,6 +251,7 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	data.ID = types.StringValue(fmt.Sprintf("%s/%s", tenantInfo.TenantID, userID))
+	data.TenantID = types.StringValue(tenantInfo.TenantID)
 	data.UserID = types.StringValue(userID)
 	data.Email = types.StringValue(email)
 	data.IsEnabled = types.BoolValue(true)
@@ -233,6 +265,10 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 }
 
 func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data, oldData userResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -241,18 +277,22 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "update", data.Timeouts.Update, defaultUpdateTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	userID := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, "")
+	tenantInfo, err := client.Tenant(ctx, "")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -260,11 +300,7 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
@@ -290,7 +326,7 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		familyName = ptr(data.FamilyName.ValueString())
 	}
 
-	err = r.client.UpdateUser(ctx, userID, email, isEnabled, isAdmin, locale, givenName, familyName)
+	err = client.UpdateUser(ctx, userID, email, isEnabled, isAdmin, locale, givenName, familyName)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot update user",
@@ -325,6 +361,10 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 }
 
 func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if !providerdata.CheckClient(r.client, &resp.Diagnostics) {
+		return
+	}
+
 	var data userResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -332,18 +372,22 @@ func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		return
 	}
 
-	parts := strings.Split(data.ID.ValueString(), "/")
-	if len(parts) != 2 {
-		resp.Diagnostics.AddError(
-			"Cannot parse ID",
-			fmt.Sprintf("Invalid ID %q", data.ID.ValueString()),
-		)
+	ctx, cancel := withTimeout(ctx, "delete", data.Timeouts.Delete, defaultDeleteTimeout, &resp.Diagnostics)
+	defer cancel()
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client := r.client.WithTenant(data.TenantID.ValueString())
+
+	parts := parseID(ctx, data.ID.ValueString(), 2, &resp.Diagnostics)
+	if parts == nil {
 		return
 	}
 	tenantID := parts[0]
 	userID := parts[1]
 
-	tenantInfo, err := r.client.Tenant(ctx, "")
+	tenantInfo, err := client.Tenant(ctx, "")
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot get tenant info",
@@ -351,15 +395,11 @@ func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 		)
 		return
 	}
-	if tenantInfo.TenantID != tenantID {
-		resp.Diagnostics.AddError(
-			"Invalid tenant",
-			fmt.Sprintf("Expected tenant %s, but got %s", tenantID, tenantInfo.TenantID),
-		)
+	if !verifyTenant(ctx, tenantInfo, tenantID, &resp.Diagnostics) {
 		return
 	}
 
-	err = r.client.DeleteUser(ctx, userID)
+	err = client.DeleteUser(ctx, userID)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Cannot delete user",
@@ -370,6 +410,5 @@ func (r *userResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 }
 
 func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	importState(ctx, r.client, 2, resolveUserID, req, resp)
 }
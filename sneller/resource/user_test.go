@@ -45,6 +45,13 @@ func TestAccResourceUser(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 			},
+			// Import testing by email address
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateId:     email,
+				ImportStateVerify: true,
+			},
 			// Update and Read testing
 			{
 				Config: baseConfig + `
@@ -55,9 +62,14 @@ func TestAccResourceUser(t *testing.T) {
 					locale      = "de-DE"
 					given_name  = "Max"
 					family_name = "Mustermann"
+
+					timeouts {
+						update = "2m"
+					}
 				}`,
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttrSet(resourceName, "id"),
+					resource.TestCheckResourceAttr(resourceName, "timeouts.update", "2m"),
 					resource.TestCheckResourceAttrSet(resourceName, "user_id"),
 					resource.TestCheckResourceAttr(resourceName, "email", email),
 					resource.TestCheckResourceAttr(resourceName, "is_admin", "false"),
@@ -0,0 +1,551 @@
+// Package snellertest provides an in-memory fake of the Sneller
+// API, so the provider can be tested without live credentials.
+package snellertest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"terraform-provider-sneller/sneller/api"
+	"time"
+)
+
+const (
+	DefaultTenantID         = "TFAKETENANT01"
+	DefaultToken            = "SF_fake-token"
+	DefaultSnellerAccountID = "000000000000"
+
+	// DefaultMaxScanBytes is the effective max-scan-bytes value
+	// when no explicit value has been set (3TiB).
+	DefaultMaxScanBytes = uint64(3 * 1024 * 1024 * 1024 * 1024)
+)
+
+// Server is an httptest based fake of the Sneller API. It keeps
+// the tenant, users, tables and Elastic proxy configuration in
+// memory.
+//
+// The region of a request is derived from the `regions` query
+// parameter (tenant info) or the region header that the client
+// sends with each request. Requests without a region are served
+// from the home region.
+type Server struct {
+	*httptest.Server
+
+	TenantID         string
+	Token            string
+	SnellerAccountID string
+	HomeRegion       string
+
+	mu         sync.Mutex
+	createdAt  time.Time
+	regions    map[string]*regionState
+	users      map[string]*api.User
+	nextUserID int
+	queries    map[queryKey]queryResult
+}
+
+type queryKey struct {
+	database, sql string
+}
+
+type queryResult struct {
+	scannedBytes uint64
+	rows         []any
+}
+
+type regionState struct {
+	bucket       string
+	roleARN      string
+	maxScanBytes *uint64
+	databases    map[string]map[string][]byte
+	indexed      map[string]bool // keyed by <database>/<table>
+	elasticProxy []byte
+}
+
+// NewServer starts a new fake Sneller API server with a single
+// active tenant that has one (admin) user. The caller should
+// call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		TenantID:         DefaultTenantID,
+		Token:            DefaultToken,
+		SnellerAccountID: DefaultSnellerAccountID,
+		HomeRegion:       api.DefaultSnellerRegion,
+		createdAt:        time.Now().UTC().Truncate(time.Second),
+		regions:          make(map[string]*regionState),
+		users:            make(map[string]*api.User),
+		queries:          make(map[queryKey]queryResult),
+	}
+	s.addUser(api.User{
+		Email:     "owner@example.com",
+		IsEnabled: true,
+		Locale:    "en-US",
+		Groups:    []string{api.AdminGroup},
+	})
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	if r.URL.Path == "/query" {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.serveQuery(w, r, s.requestRegion(r))
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "tenant" {
+		http.NotFound(w, r)
+		return
+	}
+	if parts[1] != "me" && parts[1] != s.TenantID {
+		http.Error(w, fmt.Sprintf("no access to tenant %q", parts[1]), http.StatusForbidden)
+		return
+	}
+	parts = parts[2:]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	region := s.requestRegion(r)
+	switch {
+	case len(parts) == 0:
+		s.serveTenant(w, r, region)
+	case parts[0] == "user":
+		s.serveUser(w, r, parts[1:])
+	case parts[0] == "db":
+		s.serveDatabase(w, r, region, parts[1:])
+	case len(parts) == 2 && parts[0] == "elasticproxy" && parts[1] == "config":
+		s.serveElasticProxy(w, r, region)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) requestRegion(r *http.Request) string {
+	if region := r.URL.Query().Get("regions"); region != "" {
+		return region
+	}
+	if region := r.Header.Get(api.RegionHeader); region != "" {
+		return region
+	}
+	return s.HomeRegion
+}
+
+func (s *Server) region(region string) *regionState {
+	rs := s.regions[region]
+	if rs == nil {
+		rs = &regionState{
+			databases: make(map[string]map[string][]byte),
+			indexed:   make(map[string]bool),
+		}
+		s.regions[region] = rs
+	}
+	return rs
+}
+
+func (s *Server) serveTenant(w http.ResponseWriter, r *http.Request, region string) {
+	switch r.Method {
+	case http.MethodGet:
+		activatedAt := s.createdAt
+		info := api.TenantInfo{
+			TenantID:      s.TenantID,
+			TenantState:   "active",
+			TenantName:    "Fake tenant",
+			HomeRegion:    s.HomeRegion,
+			Email:         "owner@example.com",
+			TenantRoleArn: fmt.Sprintf("arn:aws:iam::%s:role/tenant-%s", s.SnellerAccountID, s.TenantID),
+			Mfa:           api.MfaOff,
+			CreatedAt:     s.createdAt,
+			ActivatedAt:   &activatedAt,
+			Regions:       map[string]api.TenantRegionInfo{},
+		}
+		if regions := r.URL.Query().Get("regions"); regions != "" {
+			for _, region := range strings.Split(regions, ",") {
+				info.Regions[region] = s.regionInfo(region)
+			}
+		} else {
+			// all regions that are in use
+			for region := range s.regions {
+				info.Regions[region] = s.regionInfo(region)
+			}
+		}
+		writeJSON(w, info)
+
+	case http.MethodPatch:
+		rs := s.region(region)
+		q := r.URL.Query()
+		switch q.Get("operation") {
+		case "setBucket":
+			if !strings.HasPrefix(q.Get("bucket"), "s3://") || q.Get("roleArn") == "" {
+				http.Error(w, "invalid bucket or role ARN", http.StatusBadRequest)
+				return
+			}
+			rs.bucket = q.Get("bucket")
+			rs.roleARN = q.Get("roleArn")
+		case "resetBucket":
+			rs.bucket = ""
+			rs.roleARN = ""
+			rs.maxScanBytes = nil
+		case "setMaxScanBytes":
+			rs.maxScanBytes = nil
+			if v := q.Get("maxScanBytes"); v != "" {
+				maxScanBytes, err := strconv.ParseUint(v, 10, 64)
+				if err != nil {
+					http.Error(w, "invalid max-scan-bytes value", http.StatusBadRequest)
+					return
+				}
+				rs.maxScanBytes = &maxScanBytes
+			}
+			writeJSON(w, s.regionInfo(region).EffectiveMaxScanBytes)
+			return
+		default:
+			http.Error(w, fmt.Sprintf("unsupported operation %q", q.Get("operation")), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) regionInfo(region string) api.TenantRegionInfo {
+	rs := s.region(region)
+	info := api.TenantRegionInfo{
+		Bucket:                rs.bucket,
+		RegionRoleArn:         rs.roleARN,
+		RegionExternalID:      s.TenantID,
+		SqsArn:                fmt.Sprintf("arn:aws:sqs:%s:%s:tenant-sdb-%s", region, s.SnellerAccountID, s.TenantID),
+		EffectiveMaxScanBytes: DefaultMaxScanBytes,
+	}
+	if rs.maxScanBytes != nil {
+		info.MaxScanBytes = rs.maxScanBytes
+		info.EffectiveMaxScanBytes = *rs.maxScanBytes
+	}
+	return info
+}
+
+func (s *Server) addUser(user api.User) string {
+	s.nextUserID++
+	user.UserID = fmt.Sprintf("%08x-0000-4000-8000-%012x", s.nextUserID, s.nextUserID)
+	s.users[user.UserID] = &user
+	return user.UserID
+}
+
+func (s *Server) serveUser(w http.ResponseWriter, r *http.Request, parts []string) {
+	q := r.URL.Query()
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			users := make([]api.User, 0, len(s.users))
+			for _, user := range s.users {
+				u := *user
+				u.Groups = nil // only returned when fetching user details
+				users = append(users, u)
+			}
+			sort.Slice(users, func(i, j int) bool { return users[i].Email < users[j].Email })
+			writeJSON(w, users)
+		case http.MethodPost:
+			email := q.Get("email")
+			if email == "" {
+				http.Error(w, "email is required", http.StatusBadRequest)
+				return
+			}
+			for _, user := range s.users {
+				if user.Email == email {
+					http.Error(w, fmt.Sprintf("user %q already exists", email), http.StatusConflict)
+					return
+				}
+			}
+			user := api.User{
+				Email:      email,
+				IsEnabled:  true,
+				Locale:     q.Get("locale"),
+				GivenName:  q.Get("givenName"),
+				FamilyName: q.Get("familyName"),
+				Groups:     []string{},
+			}
+			if q.Get("isAdmin") == "true" {
+				user.Groups = append(user.Groups, api.AdminGroup)
+			}
+			writeJSON(w, s.addUser(user))
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	user := s.users[parts[0]]
+	if len(parts) != 1 || user == nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, user)
+	case http.MethodPatch:
+		if q.Has("isEnabled") {
+			user.IsEnabled = q.Get("isEnabled") == "true"
+		}
+		if q.Has("isAdmin") {
+			groups := []string{}
+			for _, g := range user.Groups {
+				if g != api.AdminGroup {
+					groups = append(groups, g)
+				}
+			}
+			if q.Get("isAdmin") == "true" {
+				groups = append(groups, api.AdminGroup)
+			}
+			user.Groups = groups
+		}
+		if q.Has("email") {
+			user.Email = q.Get("email")
+		}
+		if q.Has("locale") {
+			user.Locale = q.Get("locale")
+		}
+		if q.Has("givenName") {
+			user.GivenName = q.Get("givenName")
+		}
+		if q.Has("familyName") {
+			user.FamilyName = q.Get("familyName")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		delete(s.users, user.UserID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) serveDatabase(w http.ResponseWriter, r *http.Request, region string, parts []string) {
+	rs := s.region(region)
+	if len(parts) == 0 {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		databases := make([]string, 0, len(rs.databases))
+		for db := range rs.databases {
+			databases = append(databases, db)
+		}
+		sort.Strings(databases)
+		writeJSON(w, databases)
+		return
+	}
+
+	db, tables := parts[0], rs.databases[parts[0]]
+	switch {
+	case len(parts) == 2 && parts[1] == "table":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if tables == nil {
+			http.Error(w, fmt.Sprintf("database %q not found", db), http.StatusNotFound)
+			return
+		}
+		infos := make([]api.TableInfo, 0, len(tables))
+		for table := range tables {
+			infos = append(infos, api.TableInfo{Name: table, HasDefinition: true, HasIndex: rs.indexed[db+"/"+table]})
+		}
+		sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+		writeJSON(w, infos)
+
+	case len(parts) == 4 && parts[1] == "table" && parts[3] == "definition":
+		table := parts[2]
+		switch r.Method {
+		case http.MethodGet:
+			definition, ok := tables[table]
+			if !ok {
+				http.Error(w, fmt.Sprintf("table %q not found in database %q", table, db), http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(api.ETagHeader, etag(definition))
+			w.Write(definition)
+		case http.MethodPut:
+			if !checkIfMatch(w, r, tables[table]) {
+				return
+			}
+			if rs.bucket == "" {
+				http.Error(w, fmt.Sprintf("region %s has no bucket configured", region), http.StatusBadRequest)
+				return
+			}
+			definition, err := io.ReadAll(r.Body)
+			if err != nil || !json.Valid(definition) {
+				http.Error(w, "invalid table definition", http.StatusBadRequest)
+				return
+			}
+			if tables == nil {
+				tables = make(map[string][]byte)
+				rs.databases[db] = tables
+			}
+			tables[table] = definition
+			w.Header().Set(api.ETagHeader, etag(definition))
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			if _, ok := tables[table]; !ok {
+				http.Error(w, fmt.Sprintf("table %q not found in database %q", table, db), http.StatusNotFound)
+				return
+			}
+			delete(tables, table)
+			delete(rs.indexed, db+"/"+table)
+			if len(tables) == 0 {
+				delete(rs.databases, db)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveElasticProxy(w http.ResponseWriter, r *http.Request, region string) {
+	rs := s.region(region)
+	switch r.Method {
+	case http.MethodGet:
+		if rs.elasticProxy == nil {
+			http.Error(w, "elastic proxy configuration not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(api.ETagHeader, etag(rs.elasticProxy))
+		w.Write(rs.elasticProxy)
+	case http.MethodPut:
+		if !checkIfMatch(w, r, rs.elasticProxy) {
+			return
+		}
+		if rs.bucket == "" {
+			http.Error(w, fmt.Sprintf("region %s has no bucket configured", region), http.StatusBadRequest)
+			return
+		}
+		config, err := io.ReadAll(r.Body)
+		if err != nil || !json.Valid(config) {
+			http.Error(w, "invalid elastic proxy configuration", http.StatusBadRequest)
+			return
+		}
+		rs.elasticProxy = config
+		w.Header().Set(api.ETagHeader, etag(config))
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if rs.elasticProxy == nil {
+			http.Error(w, "elastic proxy configuration not found", http.StatusNotFound)
+			return
+		}
+		rs.elasticProxy = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// SetTableIndexed marks the table as indexed, as if the first
+// objects were ingested.
+func (s *Server) SetTableIndexed(region, database, table string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.region(region).indexed[database+"/"+table] = true
+}
+
+// SetQueryResult sets the rows that are returned by the SQL query
+// on the database and the number of bytes that the query scans.
+// Queries are only matched literally, so other queries fail.
+func (s *Server) SetQueryResult(database, sql string, scannedBytes uint64, rows ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[queryKey{database, sql}] = queryResult{scannedBytes: scannedBytes, rows: rows}
+}
+
+func (s *Server) serveQuery(w http.ResponseWriter, r *http.Request, region string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if accept := r.Header.Get("Accept"); accept != "" && accept != api.QueryFormatJSONLines {
+		http.Error(w, fmt.Sprintf("unsupported result format %q", accept), http.StatusNotAcceptable)
+		return
+	}
+	sql, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "cannot read query", http.StatusBadRequest)
+		return
+	}
+	database := r.URL.Query().Get("database")
+	result, ok := s.queries[queryKey{database, string(sql)}]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported query %q on database %q", sql, database), http.StatusBadRequest)
+		return
+	}
+
+	maxScanBytes := s.regionInfo(region).EffectiveMaxScanBytes
+	if value := r.URL.Query().Get("max_scan_bytes"); value != "" {
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid max_scan_bytes %q", value), http.StatusBadRequest)
+			return
+		}
+		if limit < maxScanBytes {
+			maxScanBytes = limit
+		}
+	}
+	if result.scannedBytes > maxScanBytes {
+		http.Error(w, fmt.Sprintf("query would scan %d bytes, which exceeds the limit of %d bytes", result.scannedBytes, maxScanBytes), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", api.QueryFormatJSONLines)
+	w.Header().Set("Trailer", api.BytesScannedHeader)
+	enc := json.NewEncoder(w)
+	for _, row := range result.rows {
+		enc.Encode(row)
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	w.Header().Set(api.BytesScannedHeader, strconv.FormatUint(result.scannedBytes, 10))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// etag returns the entity tag of the document.
+func etag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// checkIfMatch responds with 412 (Precondition Failed) and
+// returns false when the request has an If-Match header that
+// doesn't match the current document (nil if it doesn't exist).
+func checkIfMatch(w http.ResponseWriter, r *http.Request, current []byte) bool {
+	ifMatch := r.Header.Get(api.IfMatchHeader)
+	if ifMatch == "" || (current != nil && (ifMatch == "*" || ifMatch == etag(current))) {
+		return true
+	}
+	http.Error(w, "the document was modified", http.StatusPreconditionFailed)
+	return false
+}
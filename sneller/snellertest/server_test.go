@@ -0,0 +1,309 @@
+package snellertest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"terraform-provider-sneller/sneller/api"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func testClient(t *testing.T, srv *Server) *api.Client {
+	apiURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &api.Client{
+		Token:         srv.Token,
+		DefaultRegion: api.DefaultSnellerRegion,
+		ApiURL:        apiURL,
+		QueryURL:      apiURL,
+	}
+}
+
+func TestTenantRegion(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := testClient(t, srv)
+	region := api.DefaultSnellerRegion
+
+	if err := c.Ping(ctx, region); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if err := c.SetBucket(ctx, region, "s3://cache-bucket", "arn:aws:iam::111111111111:role/test"); err != nil {
+		t.Fatalf("set bucket: %v", err)
+	}
+	effective, err := c.SetMaxScanBytes(ctx, region, ptr(uint64(1234)))
+	if err != nil {
+		t.Fatalf("set max-scan-bytes: %v", err)
+	}
+	if effective != 1234 {
+		t.Errorf("expected effective max-scan-bytes 1234, got %d", effective)
+	}
+
+	tenantInfo, err := c.Tenant(ctx, region)
+	if err != nil {
+		t.Fatalf("tenant: %v", err)
+	}
+	regionInfo := tenantInfo.Regions[region]
+	if tenantInfo.TenantID != srv.TenantID || tenantInfo.TenantState != "active" {
+		t.Errorf("unexpected tenant %s (%s)", tenantInfo.TenantID, tenantInfo.TenantState)
+	}
+	if regionInfo.Bucket != "s3://cache-bucket" || regionInfo.RegionExternalID != srv.TenantID {
+		t.Errorf("unexpected region info %+v", regionInfo)
+	}
+	if regionInfo.MaxScanBytes == nil || *regionInfo.MaxScanBytes != 1234 {
+		t.Errorf("unexpected max-scan-bytes %v", regionInfo.MaxScanBytes)
+	}
+
+	if _, err := c.SetMaxScanBytes(ctx, region, nil); err != nil {
+		t.Fatalf("reset max-scan-bytes: %v", err)
+	}
+	if err := c.ResetBucket(ctx, region); err != nil {
+		t.Fatalf("reset bucket: %v", err)
+	}
+	tenantInfo, err = c.Tenant(ctx, region)
+	if err != nil {
+		t.Fatalf("tenant: %v", err)
+	}
+	if regionInfo := tenantInfo.Regions[region]; regionInfo.Bucket != "" || regionInfo.EffectiveMaxScanBytes != DefaultMaxScanBytes {
+		t.Errorf("unexpected region info after reset %+v", regionInfo)
+	}
+}
+
+func TestUsers(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := testClient(t, srv)
+
+	userID, err := c.CreateUser(ctx, "john.doe@example.com", true, ptr("nl-NL"), ptr("John"), nil)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	if err := c.UpdateUser(ctx, userID, nil, ptr(false), ptr(false), nil, nil, ptr("Doe")); err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	user, err := c.User(ctx, userID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+	if user.IsEnabled || slices.Contains(user.Groups, api.AdminGroup) || user.Locale != "nl-NL" || user.FamilyName != "Doe" {
+		t.Errorf("unexpected user %+v", user)
+	}
+
+	users, err := c.Users(ctx)
+	if err != nil {
+		t.Fatalf("list users: %v", err)
+	}
+	if len(users) != 2 {
+		t.Errorf("expected 2 users, got %d", len(users))
+	}
+
+	if err := c.DeleteUser(ctx, userID); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	if _, err := c.User(ctx, userID); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestTables(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := testClient(t, srv)
+	region := api.DefaultSnellerRegion
+	definition := []byte(`{"input":[{"pattern":"s3://source/*.ndjson","format":"json"}]}`)
+
+	if _, err := c.SetTable(ctx, region, "db", "table", definition, ""); err == nil {
+		t.Fatal("expected error when the region has no bucket")
+	}
+	if err := c.SetBucket(ctx, region, "s3://cache-bucket", "arn:aws:iam::111111111111:role/test"); err != nil {
+		t.Fatalf("set bucket: %v", err)
+	}
+	etag, err := c.SetTable(ctx, region, "db", "table", definition, "")
+	if err != nil {
+		t.Fatalf("set table: %v", err)
+	}
+
+	data, readETag, err := c.Table(ctx, region, "db", "table")
+	if err != nil {
+		t.Fatalf("get table: %v", err)
+	}
+	if string(data) != string(definition) {
+		t.Errorf("unexpected definition %s", data)
+	}
+	if etag == "" || readETag != etag {
+		t.Errorf("unexpected entity tags %q and %q", etag, readETag)
+	}
+
+	// conditional writes fail when the table was modified
+	modified := []byte(`{"input":[{"pattern":"s3://source/*.csv","format":"csv"}]}`)
+	newETag, err := c.SetTable(ctx, region, "db", "table", modified, etag)
+	if err != nil {
+		t.Fatalf("set table: %v", err)
+	}
+	if newETag == etag {
+		t.Errorf("expected a new entity tag, got %q", newETag)
+	}
+	if _, err := c.SetTable(ctx, region, "db", "table", definition, etag); !errors.Is(err, api.ErrPreconditionFailed) {
+		t.Errorf("expected precondition failed, got %v", err)
+	}
+	databases, err := c.Databases(ctx, region)
+	if err != nil || len(databases) != 1 || databases[0] != "db" {
+		t.Errorf("unexpected databases %v (err: %v)", databases, err)
+	}
+	tables, err := c.Database(ctx, region, "db")
+	if err != nil || len(tables) != 1 || tables[0].Name != "table" {
+		t.Errorf("unexpected tables %v (err: %v)", tables, err)
+	}
+
+	if err := c.DeleteTable(ctx, region, "db", "table", true); err != nil {
+		t.Fatalf("delete table: %v", err)
+	}
+	if _, _, err := c.Table(ctx, region, "db", "table"); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := c.Database(ctx, region, "db"); !errors.Is(err, api.ErrNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestRegions(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	// all regions share the endpoint of the test server
+	ctx := context.Background()
+	c := testClient(t, srv)
+	region := "us-west-2"
+
+	if err := c.SetBucket(ctx, region, "s3://cache-bucket", "arn:aws:iam::111111111111:role/test"); err != nil {
+		t.Fatalf("set bucket: %v", err)
+	}
+	if _, err := c.SetTable(ctx, region, "db", "table", []byte(`{"input":[]}`), ""); err != nil {
+		t.Fatalf("set table: %v", err)
+	}
+	tenantInfo, err := c.Tenant(ctx, "")
+	if err != nil {
+		t.Fatalf("tenant: %v", err)
+	}
+	if bucket := tenantInfo.Regions[region].Bucket; bucket != "s3://cache-bucket" {
+		t.Errorf("unexpected bucket %q in %s", bucket, region)
+	}
+	if bucket := tenantInfo.Regions[srv.HomeRegion].Bucket; bucket != "" {
+		t.Errorf("unexpected bucket %q in the home region", bucket)
+	}
+	if databases, err := c.Databases(ctx, region); err != nil || len(databases) != 1 {
+		t.Errorf("unexpected databases %v in %s (err: %v)", databases, region, err)
+	}
+	if databases, err := c.Databases(ctx, srv.HomeRegion); err != nil || len(databases) != 0 {
+		t.Errorf("unexpected databases %v in the home region (err: %v)", databases, err)
+	}
+}
+
+func TestElasticProxy(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := testClient(t, srv)
+	region := api.DefaultSnellerRegion
+
+	if _, _, err := c.ElasticProxyConfig(ctx, region); !errors.Is(err, api.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if err := c.SetBucket(ctx, region, "s3://cache-bucket", "arn:aws:iam::111111111111:role/test"); err != nil {
+		t.Fatalf("set bucket: %v", err)
+	}
+	config := api.ElasticProxyConfig{
+		LogPath: "s3://logs/",
+		Mapping: map[string]api.ElasticProxyMappingConfig{
+			"idx": {Database: "db", Table: "table"},
+		},
+	}
+	if _, err := c.SetElasticProxyConfig(ctx, region, config, ""); err != nil {
+		t.Fatalf("set elastic proxy: %v", err)
+	}
+	got, etag, err := c.ElasticProxyConfig(ctx, region)
+	if err != nil {
+		t.Fatalf("get elastic proxy: %v", err)
+	}
+	if got.LogPath != config.LogPath || got.Mapping["idx"].Table != "table" {
+		t.Errorf("unexpected config %+v", got)
+	}
+
+	// conditional writes fail when the configuration was modified
+	config.LogPath = "s3://other-logs/"
+	if _, err := c.SetElasticProxyConfig(ctx, region, config, etag); err != nil {
+		t.Fatalf("set elastic proxy: %v", err)
+	}
+	if _, err := c.SetElasticProxyConfig(ctx, region, config, etag); !errors.Is(err, api.ErrPreconditionFailed) {
+		t.Errorf("expected precondition failed, got %v", err)
+	}
+	if err := c.DeleteElasticProxyConfig(ctx, region); err != nil {
+		t.Fatalf("delete elastic proxy: %v", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := testClient(t, srv)
+	const sql = "SELECT COUNT(*) AS count FROM table"
+	srv.SetQueryResult("db", sql, 1000, map[string]any{"count": 42})
+
+	result, err := c.Query(ctx, "", "db", sql)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	var row struct{ Count int }
+	if err := result.Next(&row); err != nil || row.Count != 42 {
+		t.Errorf("unexpected row %+v (err: %v)", row, err)
+	}
+	if err := result.Next(&row); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF, got %v", err)
+	}
+	if n := result.BytesScanned(); n != 1000 {
+		t.Errorf("expected 1000 scanned bytes, got %d", n)
+	}
+	result.Close()
+
+	if _, err := c.Query(ctx, "", "other", sql); !errors.Is(err, api.ErrBadRequest) {
+		t.Errorf("expected bad request, got %v", err)
+	}
+	if _, err := c.QueryWithOptions(ctx, "", "db", sql, api.QueryOptions{MaxScanBytes: 999}); !errors.Is(err, api.ErrBadRequest) {
+		t.Errorf("expected bad request when exceeding max_scan_bytes, got %v", err)
+	}
+	if _, err := c.SetMaxScanBytes(ctx, api.DefaultSnellerRegion, ptr(uint64(100))); err != nil {
+		t.Fatalf("set max scan bytes: %v", err)
+	}
+	if _, err := c.Query(ctx, "", "db", sql); !errors.Is(err, api.ErrBadRequest) {
+		t.Errorf("expected bad request when exceeding the tenant's max scan bytes, got %v", err)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	c := testClient(t, srv)
+	c.Token = "invalid"
+	if _, err := c.Tenant(context.Background(), ""); !errors.Is(err, api.ErrUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+}
+
+func ptr[T any](t T) *T {
+	return &t
+}
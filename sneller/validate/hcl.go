@@ -0,0 +1,311 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"terraform-provider-sneller/sneller/resource"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hcldec"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// validateConfigFile validates the sneller_table resources of a
+// configuration file. Tables that depend on values that are only
+// known to Terraform (i.e. variables) are skipped with a warning.
+func (v *validator) validateConfigFile(ctx context.Context, filename string) ([]diagnostic, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	file, hclDiags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if hclDiags.HasErrors() {
+		return hclDiagnostics(filename, "", hclDiags), nil
+	}
+
+	var diags []diagnostic
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 || block.Labels[0] != "sneller_table" {
+			continue
+		}
+		schema, err := v.tableSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		diags = append(diags, v.validateTable(ctx, filename, block, schema)...)
+	}
+	return diags, nil
+}
+
+// validateTable validates a sneller_table resource block.
+func (v *validator) validateTable(ctx context.Context, filename string, block *hclsyntax.Block, schema *tfprotov6.Schema) []diagnostic {
+	address := fmt.Sprintf("%s.%s", block.Labels[0], block.Labels[1])
+	skipped := func(detail string) []diagnostic {
+		return []diagnostic{{
+			Severity: severityWarning,
+			File:     filename,
+			Line:     block.DefRange().Start.Line,
+			Resource: address,
+			Summary:  "Table not validated",
+			Detail:   detail,
+		}}
+	}
+
+	for _, nested := range block.Body.Blocks {
+		if nested.Type == "dynamic" {
+			return skipped("The table uses dynamic blocks, which are only expanded by Terraform.")
+		}
+	}
+
+	spec := blockSpec(schema.Block)
+	value, _, hclDiags := hcldec.PartialDecode(block.Body, spec, evalContext(filename, block.Body, spec))
+	for _, d := range hclDiags {
+		if d.Summary == "Call to unknown function" {
+			return skipped(fmt.Sprintf("%s: %s", d.Summary, d.Detail))
+		}
+	}
+	if hclDiags.HasErrors() {
+		return hclDiagnostics(filename, address, hclDiags)
+	}
+	if !value.IsWhollyKnown() {
+		return skipped("The table depends on values that are only known to Terraform (i.e. variables or other resources).")
+	}
+
+	config, err := tableConfig(value, schema)
+	if err != nil {
+		return []diagnostic{{
+			Severity: severityError,
+			File:     filename,
+			Line:     block.DefRange().Start.Line,
+			Resource: address,
+			Summary:  "Invalid table configuration",
+			Detail:   err.Error(),
+		}}
+	}
+
+	// the configuration is validated like Terraform does, before
+	// the definition that the resource writes is validated
+	configDiags, err := v.validateResourceConfig(ctx, config)
+	var diags []diagnostic
+	hasErrors := false
+	for _, d := range configDiags {
+		diag := diagnostic{
+			Severity:  severityWarning,
+			File:      filename,
+			Line:      block.DefRange().Start.Line,
+			Resource:  address,
+			Attribute: attributePath(d.Attribute),
+			Summary:   d.Summary,
+			Detail:    d.Detail,
+		}
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			diag.Severity = severityError
+			hasErrors = true
+		}
+		diags = append(diags, diag)
+	}
+	if err == nil && !hasErrors {
+		var definitionDiags []resource.TableDefinitionDiagnostic
+		definitionDiags, err = resource.ValidateTableConfig(ctx, config)
+		for _, d := range definitionDiags {
+			diags = append(diags, diagnostic{
+				Severity: severityError,
+				File:     filename,
+				Line:     block.DefRange().Start.Line,
+				Resource: address,
+				Pointer:  d.Pointer,
+				Summary:  d.Summary,
+				Detail:   d.Detail,
+			})
+		}
+	}
+	if err != nil {
+		diags = append(diags, diagnostic{
+			Severity: severityError,
+			File:     filename,
+			Line:     block.DefRange().Start.Line,
+			Resource: address,
+			Summary:  "Invalid table configuration",
+			Detail:   err.Error(),
+		})
+	}
+	return diags
+}
+
+// tableConfig converts the decoded table configuration to the
+// value that Terraform sends to the provider.
+func tableConfig(value cty.Value, schema *tfprotov6.Schema) (tftypes.Value, error) {
+	data, err := ctyjson.Marshal(value, value.Type())
+	if err != nil {
+		return tftypes.Value{}, err
+	}
+	return (&tfprotov6.DynamicValue{JSON: data}).Unmarshal(schema.ValueType())
+}
+
+// hclDiagnostics converts HCL diagnostics.
+func hclDiagnostics(filename, address string, hclDiags hcl.Diagnostics) []diagnostic {
+	var diags []diagnostic
+	for _, d := range hclDiags {
+		diag := diagnostic{
+			Severity: severityError,
+			File:     filename,
+			Resource: address,
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+		}
+		if d.Severity == hcl.DiagWarning {
+			diag.Severity = severityWarning
+		}
+		if d.Subject != nil {
+			diag.Line = d.Subject.Start.Line
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+// evalContext returns the context to evaluate a resource body. The
+// path object refers to the directory of the file and all other
+// variables are unknown. Only functions that don't depend on the
+// Terraform state are available.
+func evalContext(filename string, body hcl.Body, spec hcldec.Spec) *hcl.EvalContext {
+	dir := filepath.Dir(filename)
+	variables := make(map[string]cty.Value)
+	for _, traversal := range hcldec.Variables(body, spec) {
+		variables[traversal.RootName()] = cty.DynamicVal
+	}
+	if _, ok := variables["path"]; ok {
+		cwd, _ := os.Getwd()
+		variables["path"] = cty.ObjectVal(map[string]cty.Value{
+			"module": cty.StringVal(dir),
+			"root":   cty.StringVal(dir),
+			"cwd":    cty.StringVal(cwd),
+		})
+	}
+
+	return &hcl.EvalContext{
+		Variables: variables,
+		Functions: map[string]function.Function{
+			"concat":     stdlib.ConcatFunc,
+			"file":       fileFunc(dir),
+			"format":     stdlib.FormatFunc,
+			"join":       stdlib.JoinFunc,
+			"jsondecode": stdlib.JSONDecodeFunc,
+			"jsonencode": stdlib.JSONEncodeFunc,
+			"lower":      stdlib.LowerFunc,
+			"merge":      stdlib.MergeFunc,
+			"replace":    stdlib.ReplaceFunc,
+			"trimspace":  stdlib.TrimSpaceFunc,
+			"upper":      stdlib.UpperFunc,
+		},
+	}
+}
+
+// fileFunc returns the `file` function, which reads files relative
+// to the given directory.
+func fileFunc(dir string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			filename := args[0].AsString()
+			if !filepath.IsAbs(filename) {
+				filename = filepath.Join(dir, filename)
+			}
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(string(data)), nil
+		},
+	})
+}
+
+// blockSpec returns the specification to decode a block of the
+// given schema.
+func blockSpec(block *tfprotov6.SchemaBlock) hcldec.ObjectSpec {
+	spec := make(hcldec.ObjectSpec, len(block.Attributes)+len(block.BlockTypes))
+	for _, attribute := range block.Attributes {
+		spec[attribute.Name] = &hcldec.AttrSpec{
+			Name:     attribute.Name,
+			Type:     attributeType(attribute),
+			Required: attribute.Required,
+		}
+	}
+	for _, blockType := range block.BlockTypes {
+		nested := blockSpec(blockType.Block)
+		switch blockType.Nesting {
+		case tfprotov6.SchemaNestedBlockNestingModeList:
+			spec[blockType.TypeName] = &hcldec.BlockListSpec{TypeName: blockType.TypeName, Nested: nested}
+		case tfprotov6.SchemaNestedBlockNestingModeSet:
+			spec[blockType.TypeName] = &hcldec.BlockSetSpec{TypeName: blockType.TypeName, Nested: nested}
+		case tfprotov6.SchemaNestedBlockNestingModeMap:
+			spec[blockType.TypeName] = &hcldec.BlockMapSpec{TypeName: blockType.TypeName, LabelNames: []string{"key"}, Nested: nested}
+		default:
+			spec[blockType.TypeName] = &hcldec.BlockSpec{TypeName: blockType.TypeName, Nested: nested}
+		}
+	}
+	return spec
+}
+
+// attributeType returns the type of an attribute. The attributes
+// of nested attributes are optional, unless they are required.
+func attributeType(attribute *tfprotov6.SchemaAttribute) cty.Type {
+	if attribute.NestedType == nil {
+		return ctyType(attribute.Type)
+	}
+
+	attributes := make(map[string]cty.Type, len(attribute.NestedType.Attributes))
+	var optional []string
+	for _, nested := range attribute.NestedType.Attributes {
+		attributes[nested.Name] = attributeType(nested)
+		if !nested.Required {
+			optional = append(optional, nested.Name)
+		}
+	}
+	object := cty.ObjectWithOptionalAttrs(attributes, optional)
+	switch attribute.NestedType.Nesting {
+	case tfprotov6.SchemaObjectNestingModeList:
+		return cty.List(object)
+	case tfprotov6.SchemaObjectNestingModeSet:
+		return cty.Set(object)
+	case tfprotov6.SchemaObjectNestingModeMap:
+		return cty.Map(object)
+	}
+	return object
+}
+
+// ctyType converts a Terraform type.
+func ctyType(t tftypes.Type) cty.Type {
+	switch t := t.(type) {
+	case tftypes.List:
+		return cty.List(ctyType(t.ElementType))
+	case tftypes.Set:
+		return cty.Set(ctyType(t.ElementType))
+	case tftypes.Map:
+		return cty.Map(ctyType(t.ElementType))
+	case tftypes.Object:
+		attributes := make(map[string]cty.Type, len(t.AttributeTypes))
+		for name, attributeType := range t.AttributeTypes {
+			attributes[name] = ctyType(attributeType)
+		}
+		return cty.Object(attributes)
+	}
+	switch {
+	case t.Is(tftypes.String):
+		return cty.String
+	case t.Is(tftypes.Number):
+		return cty.Number
+	case t.Is(tftypes.Bool):
+		return cty.Bool
+	}
+	return cty.DynamicPseudoType
+}
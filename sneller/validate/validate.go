@@ -0,0 +1,232 @@
+// Package validate implements the `validate-table` command of the
+// provider binary, which validates table definitions offline.
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"terraform-provider-sneller/sneller/provider"
+	"terraform-provider-sneller/sneller/resource"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// definitionFile is the name of table definition files, when
+// directories are validated.
+const definitionFile = "definition.json"
+
+// Severities of the diagnostics.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// diagnostic is a validation result. The pointer is a JSON pointer
+// into the table definition. For tables in configuration files,
+// it refers to the definition that the resource writes. Errors of
+// the resource configuration refer to the attribute instead.
+type diagnostic struct {
+	Severity  string `json:"severity"`
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+	Pointer   string `json:"pointer"`
+	Summary   string `json:"summary"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// Run runs the `validate-table` command with the given arguments.
+// It writes a line of JSON to stdout for each diagnostic and
+// returns an error when any table definition is invalid.
+func Run(ctx context.Context, version string, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("validate-table", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: terraform-provider-sneller validate-table <path>...\n\n")
+		fmt.Fprintf(stderr, "Validates table definitions (JSON files) and the sneller_table resources of\n")
+		fmt.Fprintf(stderr, "Terraform configuration files (.tf) without accessing the Sneller API. Directories\n")
+		fmt.Fprintf(stderr, "are searched for %s and .tf files. Each diagnostic is written as a\n", definitionFile)
+		fmt.Fprintf(stderr, "line of JSON.\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("no files to validate")
+	}
+
+	files, err := findFiles(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	v := validator{version: version}
+	e := json.NewEncoder(stdout)
+	e.SetEscapeHTML(false)
+	var errorCount int
+	for _, filename := range files {
+		var diags []diagnostic
+		if strings.HasSuffix(filename, ".tf") {
+			diags, err = v.validateConfigFile(ctx, filename)
+		} else {
+			diags, err = validateDefinitionFile(filename)
+		}
+		if err != nil {
+			return err
+		}
+		for _, d := range diags {
+			if d.Severity == severityError {
+				errorCount++
+			}
+			if err := e.Encode(d); err != nil {
+				return err
+			}
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("found %d error(s) in table definitions", errorCount)
+	}
+	return nil
+}
+
+// findFiles returns the files to validate. Directories are
+// searched for table definitions and configuration files
+// (hidden directories, such as .terraform, are skipped).
+func findFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		root := path
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if !d.IsDir() && (d.Name() == definitionFile || strings.HasSuffix(d.Name(), ".tf")) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// validateDefinitionFile validates a table definition file.
+func validateDefinitionFile(filename string) ([]diagnostic, error) {
+	definition, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var diags []diagnostic
+	for _, d := range resource.ValidateTableDefinition(definition) {
+		diags = append(diags, diagnostic{
+			Severity: severityError,
+			File:     filename,
+			Pointer:  d.Pointer,
+			Summary:  d.Summary,
+			Detail:   d.Detail,
+		})
+	}
+	return diags, nil
+}
+
+// validator validates the tables of configuration files using
+// the table resource of the provider, like `terraform validate`.
+type validator struct {
+	version string
+	server  tfprotov6.ProviderServer
+	schema  *tfprotov6.Schema
+}
+
+// tableSchema returns the schema of the table resource. The schema
+// is obtained from the provider without configuring it.
+func (v *validator) tableSchema(ctx context.Context) (*tfprotov6.Schema, error) {
+	if v.schema != nil {
+		return v.schema, nil
+	}
+	server := providerserver.NewProtocol6(provider.New(v.version)())()
+	resp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get provider schema: %w", err)
+	}
+	for _, d := range resp.Diagnostics {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			return nil, fmt.Errorf("cannot get provider schema: %s: %s", d.Summary, d.Detail)
+		}
+	}
+	schema := resp.ResourceSchemas["sneller_table"]
+	if schema == nil {
+		return nil, errors.New("cannot get provider schema: no sneller_table resource")
+	}
+	v.server, v.schema = server, schema
+	return v.schema, nil
+}
+
+// validateResourceConfig validates the table configuration using
+// the validators of the table resource.
+func (v *validator) validateResourceConfig(ctx context.Context, config tftypes.Value) ([]*tfprotov6.Diagnostic, error) {
+	value, err := tfprotov6.NewDynamicValue(v.schema.ValueType(), config)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.server.ValidateResourceConfig(ctx, &tfprotov6.ValidateResourceConfigRequest{
+		TypeName: "sneller_table",
+		Config:   &value,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Diagnostics, nil
+}
+
+// attributePath formats the path of an attribute like Terraform
+// (i.e. `inputs[0].csv_hints`).
+func attributePath(p *tftypes.AttributePath) string {
+	if p == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, step := range p.Steps() {
+		switch step := step.(type) {
+		case tftypes.AttributeName:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(string(step))
+		case tftypes.ElementKeyInt:
+			fmt.Fprintf(&sb, "[%d]", step)
+		case tftypes.ElementKeyString:
+			fmt.Fprintf(&sb, "[%q]", string(step))
+		default:
+			sb.WriteString("[*]")
+		}
+	}
+	return sb.String()
+}
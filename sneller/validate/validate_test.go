@@ -0,0 +1,164 @@
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfig = `
+variable "bucket" {
+  type = string
+}
+
+resource "sneller_table" "valid" {
+  database = "db"
+  table    = "valid"
+  inputs = [{
+    pattern = "s3://source/{region}/*.ndjson"
+    format  = "json"
+    json_hints = [{
+      field = "ts"
+      hints = ["datetime"]
+    }]
+  }]
+  partitions = [{
+    field = "region"
+  }]
+  retention_policy = {
+    field     = "ts"
+    valid_for = "30d"
+  }
+}
+
+resource "sneller_table" "csv" {
+  database = "db"
+  table    = "csv"
+  inputs = [{
+    pattern = "s3://source/*.csv"
+    format  = "csv"
+    csv_hints = {
+      separator = ";"
+    }
+  }]
+}
+
+resource "sneller_table" "hints" {
+  database = "db"
+  table    = "hints"
+  inputs = [{
+    pattern = "s3://source/*.ndjson"
+    format  = "json"
+    csv_hints = {
+      separator = ";"
+    }
+  }]
+}
+
+resource "sneller_table" "blocks" {
+  database = "db"
+  table    = "blocks"
+  input {
+    pattern = "s3://source/{yyyy}/*.csv"
+    format  = "csv"
+  }
+  partitions = [{
+    field = "date"
+    value = "$yyyy-$mm"
+  }]
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+resource "sneller_table" "definition" {
+  database        = "db"
+  table           = "definition"
+  definition_json = file("${path.module}/tables/definition.json")
+}
+
+resource "sneller_table" "encoded" {
+  database = "db"
+  table    = "encoded"
+  definition_json = jsonencode({
+    input = [{ pattern = "s3://source/*.ndjson", format = "ndjson" }]
+  })
+}
+
+resource "sneller_table" "variable" {
+  database = "db"
+  table    = "variable"
+  inputs = [{
+    pattern = "s3://${var.bucket}/*.ndjson"
+    format  = "json"
+  }]
+}
+
+resource "sneller_table" "missing" {
+  table = "missing"
+}
+`
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "tables"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, ".terraform"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"main.tf":                    testConfig,
+		"tables/definition.json":     `{"input": [{"pattern": "s3://source/*.ndjson"}], "retention_policy": {"field": "ts", "valid_for": "1 year"}}`,
+		"tables/other.json":          `{"input": []}`,
+		".terraform/definition.json": `{}`,
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), "test", []string{dir}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "found 6 error(s)") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var got []string
+	d := json.NewDecoder(&stdout)
+	for d.More() {
+		var diag diagnostic
+		if err := d.Decode(&diag); err != nil {
+			t.Fatal(err)
+		}
+		file, _ := filepath.Rel(dir, diag.File)
+		got = append(got, strings.Join([]string{diag.Severity, file, diag.Resource, diag.Attribute, diag.Pointer}, " "))
+	}
+	want := []string{
+		"warning main.tf sneller_table.valid inputs[0].json_hints[0].field ",
+		"error main.tf sneller_table.hints inputs[0].csv_hints ",
+		"error main.tf sneller_table.blocks  /partitions/0/value",
+		"error main.tf sneller_table.definition  /retention_policy/valid_for",
+		"error main.tf sneller_table.encoded  /input/0/format",
+		"warning main.tf sneller_table.variable  ",
+		"error main.tf sneller_table.missing  ",
+		"error tables/definition.json   /retention_policy/valid_for",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected diagnostics:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// files are validated as definitions, unless they are
+	// configuration files
+	stdout.Reset()
+	err = Run(context.Background(), "test", []string{filepath.Join(dir, "tables", "other.json")}, &stdout, &stderr)
+	if err == nil || !strings.Contains(stdout.String(), `"pointer":"/input"`) {
+		t.Errorf("expected missing input, got %v: %s", err, stdout.String())
+	}
+}